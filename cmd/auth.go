@@ -54,7 +54,12 @@ func runAuthSetKey(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("API key looks too short — check your key at https://fal.ai/dashboard/keys")
 	}
 
-	c := &config.Config{APIKey: key}
+	// Keep the other settings already in the config file.
+	c, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	c.APIKey = key
 	if err := config.Save(c); err != nil {
 		return fmt.Errorf("saving config: %w", err)
 	}
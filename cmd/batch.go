@@ -0,0 +1,497 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/jobs"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/schema"
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <model-id> --in jobs.jsonl --out results.jsonl",
+	Short: "Run a JSONL file of payloads through the queue in parallel",
+	Long: `Submit every line of a JSONL file as a queued request to one model, with
+at most --concurrency requests in flight, and append one JSON record per
+line to the output file as each request ends:
+
+  {"line":3,"input_sha256":"...","status":"COMPLETED","request_id":"...",
+   "outputs":["https://..."],"result":{...},"started_at":"...","finished_at":"..."}
+
+Each input line is a payload object, as for "fal run --input". Lines that are
+not valid JSON, fail validation against the model's input schema, or fail
+on the queue are recorded with status FAILED (or CANCELLED) and an error;
+the other lines carry on. Local files are uploaded as for "fal run"
+(@file:<path>, or bare paths in *_url fields).
+
+With --resume, lines already COMPLETED in the output file (with the same
+input) are skipped and the remaining ones are appended, so an interrupted or
+partly failed batch can be rerun until everything completed. Without it, an
+existing non-empty output file is an error.
+
+If a budget cap (see "fal budget") is reached, no more requests are
+submitted; running ones finish, and --resume picks up the rest later.
+
+Exit status is 2 when any line failed.
+
+Examples:
+  fal batch fal-ai/flux/dev --in prompts.jsonl --out results.jsonl
+  fal batch fal-ai/flux/dev --in prompts.jsonl --out results.jsonl --concurrency 8 --download ./images
+  fal batch fal-ai/flux/dev --in prompts.jsonl --out results.jsonl --resume`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runBatch,
+	ValidArgsFunction: completeModelID,
+}
+
+var (
+	batchInFlag string
+	batchOpts   batchOptions
+)
+
+// batchOptions are the settings of a run of queued requests, shared by
+// batch, sweep and the --vary shortcuts.
+type batchOptions struct {
+	out             string // output JSONL file; none if empty
+	concurrency     int
+	resume          bool
+	timeout         time.Duration
+	noValidate      bool
+	allowOverBudget bool
+	upload          uploadOptions
+	download        downloadOptions
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&batchInFlag, "in", "", "Input JSONL file, one payload per line (- for stdin)")
+	batchCmd.Flags().StringVar(&batchOpts.out, "out", "", "Output JSONL file, one result record per line")
+	batchCmd.Flags().IntVarP(&batchOpts.concurrency, "concurrency", "c", 4, "Max requests in flight at once")
+	batchCmd.Flags().BoolVar(&batchOpts.resume, "resume", false, "Skip lines already completed in --out and append to it")
+	batchCmd.Flags().DurationVar(&batchOpts.timeout, "timeout", 0, "Cancel a request if not finished after this long, e.g. 10m (0 = no limit)")
+	batchCmd.Flags().BoolVar(&batchOpts.noValidate, "no-validate", false, "Send payloads without checking them against the model's input schema")
+	batchCmd.Flags().BoolVar(&batchOpts.allowOverBudget, "allow-over-budget", false, "Keep submitting even if a budget cap is exceeded")
+	addUploadFlags(batchCmd, &batchOpts.upload, uploadModeFal)
+	addDownloadFlags(batchCmd, &batchOpts.download)
+	_ = batchCmd.MarkFlagRequired("in")
+	_ = batchCmd.MarkFlagRequired("out")
+	rootCmd.AddCommand(batchCmd)
+}
+
+// batchLine is one request of a batch: a line of the input file, a
+// combination of a sweep, or a file of a bulk edit.
+type batchLine struct {
+	num      int
+	hash     string
+	params   map[string]any // the varied values of a sweep combination
+	payload  map[string]any
+	err      error            // the line is not a valid payload
+	done     bool             // already has its outputs: skip it
+	download *downloadOptions // overrides batchOptions.download
+}
+
+// batchRecord is one line of the output file.
+type batchRecord struct {
+	Line       int             `json:"line"`
+	InputHash  string          `json:"input_sha256"`
+	Params     map[string]any  `json:"params,omitempty"`
+	Status     string          `json:"status"` // COMPLETED, FAILED or CANCELLED
+	RequestID  string          `json:"request_id,omitempty"`
+	Outputs    []string        `json:"outputs,omitempty"`
+	Result     json.RawMessage `json:"result,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+}
+
+// batchSummary is printed when the batch ends.
+type batchSummary struct {
+	Total     int    `json:"total"`
+	Skipped   int    `json:"skipped"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+	Pending   int    `json:"pending"` // not run: interrupted or over budget
+	Out       string `json:"out,omitempty"`
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	if batchOpts.concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	lines, err := readBatchInput(batchInFlag)
+	if err != nil {
+		return err
+	}
+	return runQueuedLines(cmd, args[0], lines, &batchOpts)
+}
+
+// runQueuedLines submits lines to modelID through the queue as o says,
+// appends a record per line to o.out (if set) and prints a summary. The error
+// reflects the worst outcome: interrupted, stopped, or some lines failed.
+func runQueuedLines(cmd *cobra.Command, modelID string, lines []batchLine, o *batchOptions) error {
+	done := map[int]string{} // line → input hash of its COMPLETED record
+	if o.resume && o.out != "" {
+		var err error
+		if done, err = completedBatchLines(o.out); err != nil {
+			return err
+		}
+	} else if st, err := os.Stat(o.out); o.out != "" && err == nil && st.Size() > 0 {
+		return fmt.Errorf("%s already exists: pass --resume to continue it, or remove it", o.out)
+	}
+
+	sum := batchSummary{Total: len(lines), Out: o.out}
+	var todo []batchLine
+	for _, l := range lines {
+		if h, ok := done[l.num]; (ok && h == l.hash) || l.done {
+			sum.Skipped++
+			continue
+		}
+		todo = append(todo, l)
+	}
+
+	var ep *schema.Endpoint
+	if !o.noValidate && len(todo) > 0 {
+		var err error
+		if ep, err = loadSchema(cmd.Context(), modelID, false); err != nil {
+			if cmd.Context().Err() != nil {
+				return err
+			}
+			warnNoSchema(err)
+		}
+	}
+
+	var out *os.File
+	if o.out != "" {
+		flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+		if !o.resume {
+			flags |= os.O_TRUNC
+		}
+		var err error
+		if out, err = os.OpenFile(o.out, flags, 0644); err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	b := &batchRun{cmd: cmd, modelID: modelID, opts: o, ep: ep, out: out, total: len(todo)}
+	if len(todo) > 0 {
+		fmt.Fprintf(os.Stderr, "Running %d request(s) on %s (%d skipped, concurrency %d)...\n",
+			len(todo), modelID, sum.Skipped, o.concurrency)
+	}
+	b.run(todo)
+
+	sum.Completed = int(b.completed.Load())
+	sum.Failed = int(b.failed.Load())
+	sum.Pending = len(todo) - sum.Completed - sum.Failed
+
+	if output.IsJSON(cmd) {
+		if err := output.PrintJSON(sum, output.IsPretty(cmd)); err != nil {
+			return err
+		}
+	} else {
+		msg := fmt.Sprintf("%d completed, %d failed, %d skipped (already completed), %d not run",
+			sum.Completed, sum.Failed, sum.Skipped, sum.Pending)
+		if sum.Out != "" {
+			msg += " — results in " + sum.Out
+		}
+		fmt.Println(msg)
+	}
+
+	switch {
+	case b.writeErr != nil:
+		return fmt.Errorf("writing %s: %w", o.out, b.writeErr)
+	case cmd.Context().Err() != nil:
+		return fmt.Errorf("interrupted: %w", cmd.Context().Err())
+	case b.stopErr != nil:
+		return b.stopErr
+	case sum.Failed > 0:
+		return fmt.Errorf("%d of %d request(s) failed: %w", sum.Failed, len(todo), api.ErrRequestFailed)
+	}
+	return nil
+}
+
+// readBatchInput reads the payloads of a JSONL file, skipping blank lines.
+// Lines that do not hold a JSON object are kept with their error, so they
+// are reported in the output like any other failure.
+func readBatchInput(path string) ([]batchLine, error) {
+	var r io.Reader
+	if path == "-" {
+		r = os.Stdin
+	} else {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	var lines []batchLine
+	for num := 1; sc.Scan(); num++ {
+		text := bytes.TrimSpace(sc.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		h := sha256.Sum256(text)
+		l := batchLine{num: num, hash: hex.EncodeToString(h[:])}
+
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.UseNumber()
+		if err := dec.Decode(&l.payload); err != nil {
+			l.err = fmt.Errorf("invalid JSON: %w", err)
+		} else if l.payload == nil {
+			l.err = fmt.Errorf("invalid payload: expected a JSON object")
+		}
+		lines = append(lines, l)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return lines, nil
+}
+
+// completedBatchLines returns the lines recorded as COMPLETED in an output
+// file, with their input hash. A missing file has none.
+func completedBatchLines(path string) (map[int]string, error) {
+	done := map[int]string{}
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return done, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 64<<20)
+	for sc.Scan() {
+		var rec batchRecord
+		if json.Unmarshal(sc.Bytes(), &rec) != nil || rec.Line == 0 {
+			continue // a partly written last line
+		}
+		if rec.Status == api.StatusCompleted {
+			done[rec.Line] = rec.InputHash
+		} else {
+			delete(done, rec.Line) // the latest record wins
+		}
+	}
+	return done, sc.Err()
+}
+
+// batchRun holds the state shared by the workers of one batch.
+type batchRun struct {
+	cmd     *cobra.Command
+	modelID string
+	opts    *batchOptions
+	ep      *schema.Endpoint
+	out     *os.File
+	total   int
+
+	mu       sync.Mutex // guards out, finished and writeErr
+	finished int
+	writeErr error
+
+	completed, failed atomic.Int32
+
+	stopOnce sync.Once
+	stop     chan struct{} // closed to stop submitting new lines
+	stopErr  error
+}
+
+// run feeds lines to opts.concurrency workers and waits for them.
+func (b *batchRun) run(lines []batchLine) {
+	b.stop = make(chan struct{})
+	ctx := b.cmd.Context()
+	feed := make(chan batchLine)
+
+	var wg sync.WaitGroup
+	for i := 0; i < b.opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for l := range feed {
+				b.process(l)
+			}
+		}()
+	}
+
+send:
+	for _, l := range lines {
+		select {
+		case feed <- l:
+		case <-b.stop:
+			break send
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(feed)
+	wg.Wait()
+}
+
+// halt stops submitting new lines; lines in flight finish normally.
+func (b *batchRun) halt(err error) {
+	b.stopOnce.Do(func() {
+		b.stopErr = err
+		close(b.stop)
+	})
+}
+
+func (b *batchRun) stopped() bool {
+	select {
+	case <-b.stop:
+		return true
+	default:
+		return false
+	}
+}
+
+// process runs one line and records its outcome.
+func (b *batchRun) process(l batchLine) {
+	ctx := b.cmd.Context()
+	if b.stopped() || ctx.Err() != nil {
+		return
+	}
+	rec := batchRecord{Line: l.num, InputHash: l.hash, Params: l.params, StartedAt: time.Now().UTC()}
+	fail := func(err error) {
+		rec.Status = jobs.StatusFailed
+		rec.Error = err.Error()
+		b.record(rec)
+	}
+
+	if l.err != nil {
+		fail(l.err)
+		return
+	}
+	payload := l.payload
+	if err := validatePayload(b.modelID, b.ep, payload); err != nil {
+		fail(err)
+		return
+	}
+	refund, err := chargeBudget(b.cmd, b.modelID, payload, b.opts.allowOverBudget)
+	if err != nil {
+		// Over budget, or the budget cannot be checked: the same goes
+		// for every other line.
+		if ctx.Err() == nil {
+			fmt.Fprintf(os.Stderr, "Stopping: %s\n", err)
+			b.halt(err)
+		}
+		return
+	}
+	if err := uploadLocalRefs(b.cmd, payload, b.opts.upload); err != nil {
+		refund()
+		if ctx.Err() == nil {
+			fail(err)
+		}
+		return
+	}
+
+	sub, err := client.QueueSubmit(ctx, b.modelID, payload)
+	if err != nil {
+		refund()
+		if ctx.Err() == nil {
+			fail(err)
+		}
+		return
+	}
+	rec.RequestID = sub.RequestID
+	recordJob(jobs.Job{
+		RequestID: sub.RequestID,
+		ModelID:   b.modelID,
+		Mode:      "queue",
+		Status:    api.StatusInQueue,
+		Payload:   payload,
+	})
+
+	result, err := waitQueued(ctx, b.modelID, sub.RequestID, false, b.opts.timeout, nil)
+	finishJob(sub.RequestID, result, err)
+	if err != nil {
+		rec.Status = jobs.StatusFailed
+		var qe *api.QueueError
+		if (errors.As(err, &qe) && qe.Cancelled()) || ctx.Err() != nil {
+			rec.Status = jobs.StatusCancelled
+		}
+		rec.Error = err.Error()
+		b.record(rec)
+		return
+	}
+
+	rec.Status = api.StatusCompleted
+	rec.Outputs = resultURLs(result)
+	if json.Valid(result) {
+		rec.Result = result
+	}
+	dl := &b.opts.download
+	if l.download != nil {
+		dl = l.download
+	}
+	if err := saveOutputs(ctx, b.cmd, dl, b.modelID, sub.RequestID, payload, result); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", batchLabel(rec), err)
+	}
+	b.record(rec)
+}
+
+// record appends rec to the output file and reports progress on stderr.
+func (b *batchRun) record(rec batchRecord) {
+	rec.FinishedAt = time.Now().UTC()
+	if rec.Status == api.StatusCompleted {
+		b.completed.Add(1)
+	} else {
+		b.failed.Add(1)
+	}
+
+	data, err := json.Marshal(rec)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err == nil && b.out != nil {
+		_, err = b.out.Write(append(data, '\n'))
+	}
+	if err != nil && b.writeErr == nil {
+		b.writeErr = err
+		b.halt(err)
+	}
+
+	b.finished++
+	msg := fmt.Sprintf("[%d/%d] %s: %s", b.finished, b.total, batchLabel(rec), rec.Status)
+	if rec.RequestID != "" {
+		msg += " " + rec.RequestID
+	}
+	if rec.Error != "" {
+		msg += " — " + firstLine(rec.Error)
+	}
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(msg))
+}
+
+// batchLabel names a record in progress messages: its varied values for a
+// sweep combination, its line number otherwise.
+func batchLabel(rec batchRecord) string {
+	if len(rec.Params) == 0 {
+		return fmt.Sprintf("line %d", rec.Line)
+	}
+	keys := make([]string, 0, len(rec.Params))
+	for k := range rec.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%v", k, rec.Params[k])
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/config"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/usage"
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Show and set spending caps",
+	Long: `Every request submitted through the CLI is recorded locally with its
+estimated cost (unit price from the pricing API, cached for a day, times
+the number of images and known multipliers such as 4K at 2x). Budget caps
+are checked against that record before each submission:
+
+  daily        estimated spend since local midnight
+  monthly      estimated spend since the 1st of the month
+  per-command  estimated cost of a single request
+
+A request that would exceed a cap is refused; pass --allow-over-budget to
+submit it anyway. Caps are in the currency of model prices (USD) and are
+stored in the config file under "budget":
+
+  "budget": {"daily": 5, "monthly": 50, "per_command": 1}
+
+Requests whose cost cannot be estimated (no listed price, or a price per
+second of output) are submitted with a warning and not counted. Requests
+that fail to be submitted are not counted either.`,
+	Annotations: map[string]string{annotationOffline: "true"},
+}
+
+var budgetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the estimated spend so far against the budget caps",
+	Args:  cobra.NoArgs,
+	RunE:  runBudgetStatus,
+}
+
+var budgetSetCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set budget caps (0 removes a cap)",
+	Long: `Set one or more budget caps. Caps not given are left unchanged; 0 removes
+a cap.
+
+Examples:
+  fal budget set --daily 5 --monthly 50
+  fal budget set --per-command 1
+  fal budget set --daily 0`,
+	Args: cobra.NoArgs,
+	RunE: runBudgetSet,
+}
+
+var (
+	budgetDailyFlag      float64
+	budgetMonthlyFlag    float64
+	budgetPerCommandFlag float64
+)
+
+func init() {
+	budgetSetCmd.Flags().Float64Var(&budgetDailyFlag, "daily", 0, "Max estimated spend per day")
+	budgetSetCmd.Flags().Float64Var(&budgetMonthlyFlag, "monthly", 0, "Max estimated spend per calendar month")
+	budgetSetCmd.Flags().Float64Var(&budgetPerCommandFlag, "per-command", 0, "Max estimated cost of a single request")
+
+	budgetCmd.AddCommand(budgetStatusCmd, budgetSetCmd)
+	rootCmd.AddCommand(budgetCmd)
+}
+
+// budgetPeriod is one row of "budget status".
+type budgetPeriod struct {
+	Spent     float64  `json:"spent"`
+	Cap       float64  `json:"cap,omitempty"`
+	Remaining *float64 `json:"remaining,omitempty"`
+	Requests  int      `json:"requests"`
+}
+
+func newBudgetPeriod(spent, limit float64, requests int) budgetPeriod {
+	p := budgetPeriod{Spent: spent, Cap: limit, Requests: requests}
+	if limit > 0 {
+		r := max(limit-spent, 0)
+		p.Remaining = &r
+	}
+	return p
+}
+
+func runBudgetStatus(cmd *cobra.Command, args []string) error {
+	t, err := usage.Sum(time.Now())
+	if err != nil {
+		return err
+	}
+	b := currentBudget()
+
+	res := struct {
+		Budget config.Budget `json:"budget"`
+		Today  budgetPeriod  `json:"today"`
+		Month  budgetPeriod  `json:"month"`
+	}{
+		Budget: b,
+		Today:  newBudgetPeriod(t.Day, b.Daily, t.DayCount),
+		Month:  newBudgetPeriod(t.Month, b.Monthly, t.MonthCount),
+	}
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(res, output.IsPretty(cmd))
+	}
+
+	row := func(name string, p budgetPeriod) []string {
+		limit, remaining := "-", "-"
+		if p.Remaining != nil {
+			limit = fmt.Sprintf("%.2f", p.Cap)
+			remaining = fmt.Sprintf("%.4f", *p.Remaining)
+		}
+		return []string{name, fmt.Sprintf("%.4f", p.Spent), limit, remaining, fmt.Sprintf("%d", p.Requests)}
+	}
+	output.PrintTable([]string{"PERIOD", "SPENT", "CAP", "REMAINING", "REQUESTS"}, [][]string{
+		row("today", res.Today),
+		row("month", res.Month),
+	})
+	perCommand := "none"
+	if b.PerCommand > 0 {
+		perCommand = fmt.Sprintf("%.2f", b.PerCommand)
+	}
+	fmt.Printf("\nPer-command cap: %s (amounts in USD, estimated)\n", perCommand)
+	return nil
+}
+
+func runBudgetSet(cmd *cobra.Command, args []string) error {
+	b := currentBudget()
+	flags := cmd.Flags()
+	if !flags.Changed("daily") && !flags.Changed("monthly") && !flags.Changed("per-command") {
+		return fmt.Errorf("nothing to set: use --daily, --monthly and/or --per-command")
+	}
+	for name, v := range map[string]float64{"daily": budgetDailyFlag, "monthly": budgetMonthlyFlag, "per-command": budgetPerCommandFlag} {
+		if flags.Changed(name) && v < 0 {
+			return fmt.Errorf("--%s cannot be negative", name)
+		}
+	}
+	if flags.Changed("daily") {
+		b.Daily = budgetDailyFlag
+	}
+	if flags.Changed("monthly") {
+		b.Monthly = budgetMonthlyFlag
+	}
+	if flags.Changed("per-command") {
+		b.PerCommand = budgetPerCommandFlag
+	}
+
+	if b == (config.Budget{}) {
+		cfg.Budget = nil
+	} else {
+		cfg.Budget = &b
+	}
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	return runBudgetStatus(cmd, nil)
+}
+
+// currentBudget returns the configured caps (all zero when none are set).
+func currentBudget() config.Budget {
+	if cfg == nil || cfg.Budget == nil {
+		return config.Budget{}
+	}
+	return *cfg.Budget
+}
+
+// chargeBudget records the estimated cost of a request about to be
+// submitted to modelID, and returns a function that takes the charge back
+// if the request is then not submitted after all. It refuses the request
+// when it would exceed a budget cap, or when caps are set but the usage
+// record cannot be read, unless allowOver is set. Failing to estimate the
+// cost only warns.
+func chargeBudget(cmd *cobra.Command, modelID string, payload map[string]any, allowOver bool) (refund func(), err error) {
+	noRefund := func() {}
+	b := currentBudget()
+	est, err := estimateCost(cmd.Context(), modelID, payload)
+	if err != nil && cmd.Context().Err() != nil {
+		return noRefund, err
+	}
+	if err != nil || est.Total == nil {
+		if b != (config.Budget{}) {
+			reason := "unknown price"
+			if err != nil {
+				reason = err.Error()
+			} else if est.Note != "" {
+				reason = est.Note
+			}
+			fmt.Fprintf(os.Stderr, "Warning: cannot estimate the cost of this request (%s); it is not counted against the budget\n", reason)
+		}
+		return noRefund, nil
+	}
+
+	e := usage.Entry{
+		At:       time.Now(),
+		ModelID:  modelID,
+		Command:  cmd.CommandPath(),
+		Cost:     *est.Total,
+		Currency: est.Currency,
+	}
+	err = usage.Charge(e, &b, false)
+	var over *usage.OverBudgetError
+	if errors.As(err, &over) {
+		if !allowOver {
+			return noRefund, fmt.Errorf("budget exceeded: %w (pass --allow-over-budget to submit anyway)", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: over budget: %s; submitting anyway\n", err)
+		err = usage.Charge(e, &b, true)
+	}
+	if err != nil {
+		if b != (config.Budget{}) && !allowOver {
+			return noRefund, fmt.Errorf("cannot check the budget: %w (pass --allow-over-budget to submit anyway)", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: could not record usage: %s\n", err)
+		return noRefund, nil
+	}
+
+	return func() {
+		if err := usage.Refund(e); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not take back the charge of an unsubmitted request: %s\n", err)
+		}
+	}, nil
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// bulkImageExts are the files a bulk edit picks up when no --glob is given.
+var bulkImageExts = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".webp": true}
+
+// bulkEditOptions holds the flags that run an edit shortcut over a
+// directory of images.
+type bulkEditOptions struct {
+	dir         string
+	glob        string
+	out         string
+	concurrency int
+}
+
+// addBulkEditFlags registers --dir, --glob, --out and --concurrency on cmd.
+func addBulkEditFlags(cmd *cobra.Command, o *bulkEditOptions) {
+	cmd.Flags().StringVar(&o.dir, "dir", "",
+		"Edit every image under this directory, each as its own request (requires --out)")
+	cmd.Flags().StringVar(&o.glob, "glob", "",
+		"With --dir, only files matching this pattern, e.g. '*.jpg' (default: jpg, jpeg, png, webp)")
+	cmd.Flags().StringVar(&o.out, "out", "",
+		"With --dir, directory for the outputs, mirroring the input tree")
+	cmd.Flags().IntVarP(&o.concurrency, "concurrency", "c", 4,
+		"With --dir, max requests in flight at once")
+}
+
+// bulkInput is one image found under --dir.
+type bulkInput struct {
+	path string // as found, under --dir
+	rel  string // relative to --dir
+}
+
+// findBulkInputs walks dir for the files matching glob (against the path
+// relative to dir when glob has a "/", the base name otherwise), leaving
+// out hidden entries and the output directory.
+func findBulkInputs(dir, glob, out string) ([]bulkInput, error) {
+	if glob != "" {
+		if _, err := filepath.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid --glob %q: %w", glob, err)
+		}
+	}
+	outAbs, err := filepath.Abs(out)
+	if err != nil {
+		return nil, err
+	}
+
+	var inputs []bulkInput
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if abs, err := filepath.Abs(path); err == nil && abs == outAbs {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		switch {
+		case glob == "":
+			ok = bulkImageExts[strings.ToLower(filepath.Ext(path))]
+		case strings.Contains(glob, "/"):
+			ok, _ = filepath.Match(glob, filepath.ToSlash(rel))
+		default:
+			ok, _ = filepath.Match(glob, d.Name())
+		}
+		if ok {
+			inputs = append(inputs, bulkInput{path: path, rel: rel})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(inputs, func(i, j int) bool { return inputs[i].rel < inputs[j].rel })
+	return inputs, nil
+}
+
+// hasBulkOutput reports whether dir holds an output for an input named
+// stem, whatever the extension: stem.<ext>, or stem_<n>.<ext> when the
+// edit asks for num > 1 images.
+func hasBulkOutput(dir, stem string, num int) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		if num <= 1 {
+			if name == stem {
+				return true
+			}
+			continue
+		}
+		if n, ok := strings.CutPrefix(name, stem+"_"); ok && n != "" && strings.Trim(n, "0123456789") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// runBulkEdit runs an edit shortcut once per image under o.dir, through the
+// queue as "fal batch" does. Each image is sent first in image_urls, after
+// it extra (the --image and --file sources, shared by every request), and
+// its outputs are saved under o.out at the same relative path, as
+// <name>.<ext> (or <name>_<n>.<ext> when num > 1). Images that already have
+// an output there are skipped. Two images whose names differ only by
+// extension would overwrite each other's outputs, so they are refused.
+func runBulkEdit(cmd *cobra.Command, modelID string, o *bulkEditOptions, s *submitOptions, u uploadOptions,
+	extraURLs, extraFiles []string, num int, payload func(imageURLs []string) map[string]any) error {
+	switch {
+	case o.dir == "":
+		return fmt.Errorf("--out needs --dir")
+	case o.out == "":
+		return fmt.Errorf("--dir needs --out")
+	case s.download.dir != "":
+		return fmt.Errorf("--download does not apply with --dir: outputs go to --out")
+	case o.concurrency < 1:
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if st, err := os.Stat(o.dir); err != nil {
+		return err
+	} else if !st.IsDir() {
+		return fmt.Errorf("%s is not a directory", o.dir)
+	}
+
+	extra := append([]string(nil), extraURLs...)
+	for _, f := range extraFiles {
+		if _, err := os.Stat(f); err != nil {
+			return err
+		}
+		extra = append(extra, fileRefPrefix+f)
+	}
+
+	inputs, err := findBulkInputs(o.dir, o.glob, o.out)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no images found in %s", o.dir)
+	}
+
+	stems := make(map[string]string, len(inputs)) // output path without extension → input
+	for _, in := range inputs {
+		key := strings.TrimSuffix(in.rel, filepath.Ext(in.rel))
+		if prev, ok := stems[key]; ok {
+			return fmt.Errorf("%s and %s would both be saved as %s.*; rename one or narrow --glob",
+				prev, in.rel, filepath.Join(o.out, key))
+		}
+		stems[key] = in.rel
+	}
+
+	lines := make([]batchLine, 0, len(inputs))
+	for i, in := range inputs {
+		stem := strings.TrimSuffix(filepath.Base(in.rel), filepath.Ext(in.rel))
+		dl := downloadOptions{
+			dir:      filepath.Join(o.out, filepath.Dir(in.rel)),
+			template: stem + ".{ext}",
+			sidecar:  s.download.sidecar,
+		}
+		if num > 1 {
+			dl.template = stem + "_{index}.{ext}"
+		}
+
+		urls := append([]string{fileRefPrefix + in.path}, extra...)
+		l := sweepLine(i+1, map[string]any{"file": filepath.ToSlash(in.rel)}, payload(urls))
+		l.download = &dl
+		l.done = hasBulkOutput(dl.dir, stem, num)
+		lines = append(lines, l)
+	}
+
+	if s.dryRun {
+		var todo []batchLine
+		for _, l := range lines {
+			if !l.done {
+				todo = append(todo, l)
+			}
+		}
+		if skipped := len(lines) - len(todo); skipped > 0 {
+			fmt.Fprintf(os.Stderr, "%d image(s) already have outputs in %s and would be skipped.\n", skipped, o.out)
+		}
+		return printSweepDryRun(cmd, modelID, todo)
+	}
+
+	return runQueuedLines(cmd, modelID, lines, &batchOptions{
+		concurrency:     o.concurrency,
+		timeout:         s.timeout,
+		noValidate:      true, // the shortcuts send what their flags allow
+		allowOverBudget: s.allowOverBudget,
+		upload:          u,
+	})
+}
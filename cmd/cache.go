@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/uploadcache"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:         "cache",
+	Short:       "Manage the local upload cache",
+	Annotations: map[string]string{annotationOffline: "true"},
+	Long: `Local files sent to fal storage or to your S3 bucket (--file, @file:
+references, ...) are cached by the SHA-256 of their contents in uploads.json
+in the config dir. Sending the same file again reuses the earlier URL until
+it expires, instead of uploading it again. Pass --no-upload-cache to a
+command to bypass it.`,
+}
+
+var cacheLsCmd = &cobra.Command{
+	Use:     "ls",
+	Aliases: []string{"list"},
+	Short:   "List cached uploads, most recent first",
+	Args:    cobra.NoArgs,
+	RunE:    runCacheLs,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired entries from the upload cache",
+	Long: `Remove expired entries from the upload cache. The uploaded files themselves
+are not deleted.
+
+Examples:
+  fal cache prune
+  fal cache prune --all`,
+	Args: cobra.NoArgs,
+	RunE: runCachePrune,
+}
+
+var cachePruneAll bool
+
+func init() {
+	cachePruneCmd.Flags().BoolVar(&cachePruneAll, "all", false, "Remove every entry, not only expired ones")
+
+	cacheCmd.AddCommand(cacheLsCmd, cachePruneCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+func runCacheLs(cmd *cobra.Command, args []string) error {
+	list, err := uploadcache.List()
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON(cmd) {
+		if list == nil {
+			list = []uploadcache.Entry{}
+		}
+		return output.PrintJSON(list, output.IsPretty(cmd))
+	}
+
+	if len(list) == 0 {
+		fmt.Println("Upload cache is empty.")
+		return nil
+	}
+
+	now := time.Now()
+	headers := []string{"SHA256", "BACKEND", "NAME", "SIZE", "EXPIRES", "URL"}
+	rows := make([][]string, len(list))
+	for i, e := range list {
+		expires := e.ExpiresAt.Local().Format("2006-01-02 15:04")
+		if e.Expired(now) {
+			expires = "expired"
+		}
+		hash := e.Hash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		rows[i] = []string{
+			hash,
+			e.Backend,
+			output.Truncate(e.Name, 30),
+			formatBytes(e.Size),
+			expires,
+			e.URL,
+		}
+	}
+	output.PrintTable(headers, rows)
+	return nil
+}
+
+func runCachePrune(cmd *cobra.Command, args []string) error {
+	n, err := uploadcache.Prune(cachePruneAll)
+	if err != nil {
+		return err
+	}
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(map[string]int{"removed": n}, output.IsPretty(cmd))
+	}
+	if n == 1 {
+		fmt.Println("Removed 1 cache entry.")
+	} else {
+		fmt.Printf("Removed %d cache entries.\n", n)
+	}
+	return nil
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/catalog"
+	"github.com/the20100/fal-cli/internal/output"
+)
+
+// pricingBatch is how many endpoint IDs are priced per request during sync.
+const pricingBatch = 50
+
+// catalogStaleAfter is the age past which offline listings warn that the
+// local catalog may be out of date.
+const catalogStaleAfter = 7 * 24 * time.Hour
+
+var modelsSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Download the model catalog and pricing for offline use",
+	Long: `Download the whole model catalog and the price of every model into the
+config dir, for "fal models search" and "fal models list --offline".
+
+After the first sync, the models added or removed since the previous sync,
+and those whose status or price changed, are listed.
+
+Examples:
+  fal models sync
+  fal models sync --json | jq '.changes[] | select(.kind == "price")'`,
+	Args: cobra.NoArgs,
+	RunE: runModelsSync,
+}
+
+var modelsSearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Fuzzy-search the local model catalog",
+	Long: `Search the catalog synced by "fal models sync", without network access.
+
+Every word of the query must match the endpoint ID, name, tags or
+description of a model. Matching is fuzzy: word prefixes, small typos
+("upscalr") and in-order abbreviations of the ID or name ("fdev" for
+fal-ai/flux/dev) all match, with whole-word hits in the ID or name ranked
+first.
+
+Examples:
+  fal models search flux
+  fal models search "image to video" --category image-to-video
+  fal models search upscaler --limit 5 --json`,
+	Args:        cobra.MinimumNArgs(1),
+	Annotations: map[string]string{annotationOffline: "true"},
+	RunE:        runModelsSearch,
+}
+
+var (
+	searchCategoryFlag string
+	searchLimitFlag    int
+)
+
+// syncResult is the JSON output of "models sync".
+type syncResult struct {
+	SyncedAt      time.Time        `json:"synced_at"`
+	Models        int              `json:"models"`
+	Prices        int              `json:"prices"`
+	FirstSync     bool             `json:"first_sync"`
+	PreviousSync  *time.Time       `json:"previous_sync,omitempty"`
+	Changes       []catalog.Change `json:"changes"`
+	PricingFailed string           `json:"pricing_error,omitempty"`
+}
+
+func init() {
+	modelsSearchCmd.Flags().StringVar(&searchCategoryFlag, "category", "", "Only models in this category")
+	modelsSearchCmd.Flags().IntVar(&searchLimitFlag, "limit", 20, "Max number of models to return (0 for all)")
+
+	modelsCmd.AddCommand(modelsSyncCmd, modelsSearchCmd)
+}
+
+func runModelsSync(cmd *cobra.Command, args []string) error {
+	progress := isatty.IsTerminal(os.Stderr.Fd())
+
+	models, err := client.ModelPages(cmd.Context(), "", "", "", 0).All()
+	if err != nil {
+		return fmt.Errorf("fetching the catalog: %w", err)
+	}
+	if progress {
+		fmt.Fprintf(os.Stderr, "Fetched %d models, fetching prices...\n", len(models))
+	}
+
+	cur := &catalog.Catalog{SyncedAt: time.Now().UTC(), Models: models}
+	res := syncResult{SyncedAt: cur.SyncedAt, Models: len(models)}
+
+	// Pricing is looked up by endpoint ID, in batches to keep URLs short.
+	for start := 0; start < len(models); start += pricingBatch {
+		end := min(start+pricingBatch, len(models))
+		ids := make([]string, 0, end-start)
+		for _, m := range models[start:end] {
+			ids = append(ids, m.EndpointID)
+		}
+		prices, err := client.PricingPages(cmd.Context(), ids, "", 0).All()
+		if err != nil {
+			// Keep the prices from the last sync rather than losing them.
+			res.PricingFailed = err.Error()
+			fmt.Fprintf(os.Stderr, "Warning: could not fetch pricing, keeping the previous prices: %s\n", err)
+			cur.Prices = nil
+			break
+		}
+		cur.Prices = append(cur.Prices, prices...)
+	}
+
+	prev, err := catalog.Load()
+	switch {
+	case errors.Is(err, catalog.ErrNotSynced):
+		res.FirstSync = true
+		prev = &catalog.Catalog{}
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Warning: could not read the previous catalog: %s\n", err)
+		res.FirstSync = true
+		prev = &catalog.Catalog{}
+	default:
+		res.PreviousSync = &prev.SyncedAt
+	}
+	if res.PricingFailed != "" {
+		cur.Prices = prev.Prices
+	}
+	res.Prices = len(cur.Prices)
+
+	if !res.FirstSync {
+		res.Changes = catalog.Diff(prev, cur)
+	}
+	if res.Changes == nil {
+		res.Changes = []catalog.Change{}
+	}
+
+	if err := catalog.Store(cur); err != nil {
+		return fmt.Errorf("saving the catalog: %w", err)
+	}
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(res, output.IsPretty(cmd))
+	}
+
+	fmt.Printf("Synced %d models (%d priced).\n", res.Models, res.Prices)
+	if res.FirstSync {
+		return nil
+	}
+	if len(res.Changes) == 0 {
+		fmt.Printf("No changes since the last sync (%s).\n", res.PreviousSync.Local().Format("2006-01-02 15:04"))
+		return nil
+	}
+
+	fmt.Printf("\nChanges since %s:\n", res.PreviousSync.Local().Format("2006-01-02 15:04"))
+	rows := make([][]string, len(res.Changes))
+	for i, c := range res.Changes {
+		rows[i] = []string{changeMark(c.Kind), c.EndpointID, changeDetail(c)}
+	}
+	output.PrintTable([]string{"", "ENDPOINT ID", "CHANGE"}, rows)
+	return nil
+}
+
+func changeMark(kind string) string {
+	switch kind {
+	case catalog.Added:
+		return "+"
+	case catalog.Removed:
+		return "-"
+	}
+	return "~"
+}
+
+func changeDetail(c catalog.Change) string {
+	switch c.Kind {
+	case catalog.Added:
+		detail := "added"
+		if c.Name != "" {
+			detail += ": " + c.Name
+		}
+		if c.NewPrice != nil {
+			detail += " (" + formatModelPrice(*c.NewPrice) + ")"
+		}
+		return detail
+	case catalog.Removed:
+		return "removed"
+	case catalog.StatusChanged:
+		return fmt.Sprintf("status %s → %s", orDash(c.OldStatus), orDash(c.NewStatus))
+	case catalog.PriceChanged:
+		return fmt.Sprintf("price %s → %s", formatModelPrice(*c.OldPrice), formatModelPrice(*c.NewPrice))
+	}
+	return c.Kind
+}
+
+func formatModelPrice(p api.ModelPrice) string {
+	return output.FormatPrice(p.UnitPrice, p.Unit, strings.ToUpper(p.Currency))
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func runModelsSearch(cmd *cobra.Command, args []string) error {
+	c, err := loadCatalog()
+	if err != nil {
+		return err
+	}
+	models := filterCategory(catalog.Search(c.Models, strings.Join(args, " ")), searchCategoryFlag)
+	if searchLimitFlag > 0 && len(models) > searchLimitFlag {
+		models = models[:searchLimitFlag]
+	}
+	return printCatalogModels(cmd, c, models)
+}
+
+// loadCatalog loads the local catalog, warning when it is old.
+func loadCatalog() (*catalog.Catalog, error) {
+	c, err := catalog.Load()
+	if err != nil {
+		return nil, err
+	}
+	if age := time.Since(c.SyncedAt); age > catalogStaleAfter {
+		fmt.Fprintf(os.Stderr, "Warning: the local catalog was synced %d days ago; run \"fal models sync\" to refresh it\n", int(age.Hours()/24))
+	}
+	return c, nil
+}
+
+func filterCategory(models []api.Model, category string) []api.Model {
+	if category == "" {
+		return models
+	}
+	var out []api.Model
+	for _, m := range models {
+		if strings.EqualFold(m.Metadata.Category, category) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// printCatalogModels prints models from the local catalog, with their price.
+func printCatalogModels(cmd *cobra.Command, c *catalog.Catalog, models []api.Model) error {
+	if output.IsJSON(cmd) {
+		if models == nil {
+			models = []api.Model{}
+		}
+		return output.PrintJSON(models, output.IsPretty(cmd))
+	}
+
+	if len(models) == 0 {
+		fmt.Println("No models found.")
+		return nil
+	}
+
+	headers := []string{"ENDPOINT ID", "NAME", "CATEGORY", "STATUS", "PRICE"}
+	rows := make([][]string, len(models))
+	for i, m := range models {
+		price := "-"
+		if p, ok := c.Price(m.EndpointID); ok {
+			price = formatModelPrice(p)
+		}
+		rows[i] = []string{
+			m.EndpointID,
+			output.Truncate(m.Metadata.DisplayName, 35),
+			m.Metadata.Category,
+			m.Metadata.Status,
+			price,
+		}
+	}
+	output.PrintTable(headers, rows)
+	return nil
+}
@@ -0,0 +1,418 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/jobs"
+	"github.com/the20100/fal-cli/internal/output"
+)
+
+// compareSaveTimeout bounds the download of completed models' outputs
+// after the comparison was interrupted.
+const compareSaveTimeout = time.Minute
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <prompt> --model <model-id> --model <model-id>...",
+	Short: "Run one prompt on several models side by side",
+	Long: `Run the same prompt on several text-to-image models at once and report
+each model's status, latency, estimated cost and output URLs side by side.
+
+The common settings (--aspect, --num, --seed) are mapped onto each model's
+own payload shape: image_size presets or width/height for flux/dev,
+flux/schnell and gpt-image-2, aspect_ratio for nano-banana. Other models
+get prompt, num_images, aspect_ratio and seed. Fields a model's input
+schema does not list are left out.
+
+Requests go through the queue and are recorded in the job ledger and the
+budget like any other. With --download, each model's outputs are saved in
+a folder of their own (DIR/<model>, "/" replaced by "-").
+
+Exit status is 2 when any model failed.
+
+Examples:
+  fal compare "a lighthouse at dusk" --model openai/gpt-image-2 --model fal-ai/nano-banana-2
+  fal compare "a red fox" --model fal-ai/flux/dev --model fal-ai/flux/schnell --aspect 16:9 --num 2
+  fal compare "a red fox" --model fal-ai/flux/dev --model fal-ai/nano-banana-2 -o ./compare
+  fal compare "a red fox" --model openai/gpt-image-2 --model fal-ai/nano-banana-2 --dry-run`,
+	Args: cobra.ExactArgs(1),
+	RunE: runCompare,
+}
+
+var (
+	compareModels          []string
+	compareAspect          string
+	compareNum             int
+	compareSeed            int64
+	compareTimeout         time.Duration
+	compareDryRun          bool
+	compareAllowOverBudget bool
+	compareDownload        downloadOptions
+)
+
+func init() {
+	compareCmd.Flags().StringArrayVarP(&compareModels, "model", "m", nil, "Model to run (repeatable)")
+	compareCmd.Flags().StringVar(&compareAspect, "aspect", "1:1", "Aspect ratio, e.g. 1:1, 16:9, 9:16, 4:3")
+	compareCmd.Flags().IntVar(&compareNum, "num", 1, "Number of images per model")
+	compareCmd.Flags().Int64Var(&compareSeed, "seed", 0, "Random seed, for models that take one (0 = random)")
+	compareCmd.Flags().DurationVar(&compareTimeout, "timeout", 0, "Cancel a model's request if not finished after this long, e.g. 5m (0 = no limit)")
+	compareCmd.Flags().BoolVar(&compareDryRun, "dry-run", false, "Print each model's payload and estimated cost without submitting anything")
+	compareCmd.Flags().BoolVar(&compareAllowOverBudget, "allow-over-budget", false, "Submit even if the estimated cost exceeds a budget cap (see fal budget)")
+	addDownloadFlags(compareCmd, &compareDownload)
+	_ = compareCmd.MarkFlagRequired("model")
+	_ = compareCmd.RegisterFlagCompletionFunc("model", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return modelCompletions(toComplete, compareModels), cobra.ShellCompDirectiveNoFileComp
+	})
+	rootCmd.AddCommand(compareCmd)
+}
+
+// compareRequest holds the settings common to every model of a comparison.
+type compareRequest struct {
+	prompt string
+	aspect string // "W:H"
+	num    int
+	seed   int64 // 0 = unset
+}
+
+// compareAdapter maps the common settings onto a model's payload.
+type compareAdapter func(r compareRequest) map[string]any
+
+// compareAdapters holds the payload shapes of known text-to-image
+// endpoints, keyed by endpoint ID. Other models, including the edit and
+// pro/kontext endpoints of the same families, get genericPayload.
+var compareAdapters = map[string]compareAdapter{
+	"openai/gpt-image-2":     gptImagePayload,
+	"fal-ai/nano-banana":     bananaPayload,
+	"fal-ai/nano-banana-2":   bananaPayload,
+	"fal-ai/nano-banana-pro": bananaPayload,
+	"fal-ai/flux/dev":        fluxPayload,
+	"fal-ai/flux/schnell":    fluxPayload,
+}
+
+// gptImagePayload: GPT Image takes an explicit image_size and no seed.
+func gptImagePayload(r compareRequest) map[string]any {
+	w, h := aspectSize(r.aspect, 2048)
+	return map[string]any{
+		"prompt":        r.prompt,
+		"image_size":    map[string]int{"width": w, "height": h},
+		"quality":       "medium",
+		"num_images":    r.num,
+		"output_format": "png",
+	}
+}
+
+// bananaPayload: nano-banana takes the aspect ratio as is.
+func bananaPayload(r compareRequest) map[string]any {
+	p := map[string]any{
+		"prompt":        r.prompt,
+		"aspect_ratio":  r.aspect,
+		"num_images":    r.num,
+		"output_format": "png",
+	}
+	if r.seed != 0 {
+		p["seed"] = r.seed
+	}
+	return p
+}
+
+// fluxPresets are the image_size presets of flux models.
+var fluxPresets = map[string]string{
+	"1:1":  "square_hd",
+	"4:3":  "landscape_4_3",
+	"16:9": "landscape_16_9",
+	"3:4":  "portrait_4_3",
+	"9:16": "portrait_16_9",
+}
+
+// fluxPayload: flux takes an image_size preset, or width/height for other
+// ratios.
+func fluxPayload(r compareRequest) map[string]any {
+	var size any = fluxPresets[r.aspect]
+	if size == "" {
+		w, h := aspectSize(r.aspect, 1024)
+		size = map[string]int{"width": w, "height": h}
+	}
+	p := map[string]any{
+		"prompt":     r.prompt,
+		"image_size": size,
+		"num_images": r.num,
+	}
+	if r.seed != 0 {
+		p["seed"] = r.seed
+	}
+	return p
+}
+
+// genericPayload is the best guess for other models; runCompare drops the
+// fields their input schema does not have.
+func genericPayload(r compareRequest) map[string]any {
+	p := map[string]any{
+		"prompt":       r.prompt,
+		"aspect_ratio": r.aspect,
+		"num_images":   r.num,
+	}
+	if r.seed != 0 {
+		p["seed"] = r.seed
+	}
+	return p
+}
+
+func compareAdapterFor(modelID string) compareAdapter {
+	if a, ok := compareAdapters[modelID]; ok {
+		return a
+	}
+	return genericPayload
+}
+
+// parseAspect splits a "W:H" aspect ratio.
+func parseAspect(s string) (w, h int, err error) {
+	a, b, ok := strings.Cut(s, ":")
+	w, err1 := strconv.Atoi(a)
+	h, err2 := strconv.Atoi(b)
+	if !ok || err1 != nil || err2 != nil || w <= 0 || h <= 0 {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q: expected W:H, e.g. 16:9", s)
+	}
+	return w, h, nil
+}
+
+// aspectSize returns the width and height of the given aspect ratio with
+// the long side at long pixels, rounded to multiples of 16.
+func aspectSize(aspect string, long int) (int, int) {
+	w, h, err := parseAspect(aspect)
+	if err != nil {
+		return long, long
+	}
+	round := func(v float64) int { return max(16, int(v/16+0.5)*16) }
+	if w >= h {
+		return long, round(float64(long) * float64(h) / float64(w))
+	}
+	return round(float64(long) * float64(w) / float64(h)), long
+}
+
+// compareResult is one model's outcome.
+type compareResult struct {
+	ModelID   string         `json:"model_id"`
+	Status    string         `json:"status,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Latency   float64        `json:"latency_seconds,omitempty"`
+	Estimate  *costEstimate  `json:"estimate,omitempty"`
+	Outputs   []string       `json:"outputs,omitempty"`
+	Error     string         `json:"error,omitempty"`
+	Payload   map[string]any `json:"payload"`
+
+	result []byte
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	req := compareRequest{prompt: args[0], aspect: compareAspect, num: compareNum, seed: compareSeed}
+	if _, _, err := parseAspect(req.aspect); err != nil {
+		return err
+	}
+	if req.num < 1 {
+		return fmt.Errorf("--num must be at least 1")
+	}
+
+	ctx := cmd.Context()
+	results := make([]*compareResult, 0, len(compareModels))
+	seen := map[string]bool{}
+	for _, m := range compareModels {
+		if seen[m] {
+			return fmt.Errorf("--model %s is given more than once", m)
+		}
+		seen[m] = true
+
+		payload := compareAdapterFor(m)(req)
+		ep, err := loadSchema(ctx, m, false)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Warning: %s: no input schema, sending the payload unfiltered: %s\n", m, err)
+		} else if in := ep.Resolve(ep.Input); in != nil {
+			for k := range payload {
+				if in.Properties[k] == nil {
+					delete(payload, k)
+				}
+			}
+		}
+		r := &compareResult{ModelID: m, Payload: payload}
+		if est, err := estimateCost(ctx, m, payload); err == nil {
+			r.Estimate = est
+		} else if ctx.Err() != nil {
+			return err
+		}
+		results = append(results, r)
+	}
+
+	if compareDryRun {
+		return printCompareDryRun(cmd, results)
+	}
+
+	fmt.Fprintf(os.Stderr, "Running %d model(s)...\n", len(results))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	finished := 0
+	for _, r := range results {
+		wg.Add(1)
+		go func(r *compareResult) {
+			defer wg.Done()
+			runCompared(cmd, r)
+			mu.Lock()
+			defer mu.Unlock()
+			finished++
+			msg := fmt.Sprintf("[%d/%d] %s: %s", finished, len(results), r.ModelID, r.Status)
+			if r.Error != "" {
+				msg += " — " + firstLine(r.Error)
+			} else {
+				msg += fmt.Sprintf(" in %.1fs", r.Latency)
+			}
+			fmt.Fprintln(os.Stderr, msg)
+		}(r)
+	}
+	wg.Wait()
+
+	if compareDownload.dir != "" {
+		// Completed models are paid for: save their outputs even after
+		// Ctrl-C, within a deadline of their own.
+		dlCtx := ctx
+		if ctx.Err() != nil {
+			var cancel context.CancelFunc
+			dlCtx, cancel = context.WithTimeout(context.Background(), compareSaveTimeout)
+			defer cancel()
+		}
+		for _, r := range results {
+			if r.Status != api.StatusCompleted {
+				continue
+			}
+			o := compareDownload
+			o.dir = filepath.Join(compareDownload.dir, strings.ReplaceAll(strings.Trim(r.ModelID, "/"), "/", "-"))
+			if err := saveOutputs(dlCtx, cmd, &o, r.ModelID, r.RequestID, r.Payload, r.result); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", r.ModelID, err)
+			}
+		}
+	}
+
+	if err := printCompareResults(cmd, results); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted: %w", ctx.Err())
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Status != api.StatusCompleted {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d model(s) failed: %w", failed, len(results), api.ErrRequestFailed)
+	}
+	return nil
+}
+
+// runCompared submits r's payload through the queue and waits for it,
+// filling in the outcome.
+func runCompared(cmd *cobra.Command, r *compareResult) {
+	ctx := cmd.Context()
+	r.Status = jobs.StatusFailed
+	refund, err := chargeBudget(cmd, r.ModelID, r.Payload, compareAllowOverBudget)
+	if err != nil {
+		r.Error = err.Error()
+		return
+	}
+
+	start := time.Now()
+	sub, err := client.QueueSubmit(ctx, r.ModelID, r.Payload)
+	if err != nil {
+		refund()
+		r.Error = err.Error()
+		return
+	}
+	r.RequestID = sub.RequestID
+	recordJob(jobs.Job{
+		RequestID: sub.RequestID,
+		ModelID:   r.ModelID,
+		Mode:      "queue",
+		Status:    api.StatusInQueue,
+		Payload:   r.Payload,
+	})
+
+	result, err := waitQueued(ctx, r.ModelID, sub.RequestID, false, compareTimeout, nil)
+	r.Latency = time.Since(start).Seconds()
+	finishJob(sub.RequestID, result, err)
+	if err != nil {
+		var qe *api.QueueError
+		if (errors.As(err, &qe) && qe.Cancelled()) || ctx.Err() != nil {
+			r.Status = jobs.StatusCancelled
+		}
+		r.Error = err.Error()
+		return
+	}
+	r.Status = api.StatusCompleted
+	r.Outputs = resultURLs(result)
+	r.result = result
+}
+
+// printCompareResults prints one row per model, with further rows for
+// extra output URLs.
+func printCompareResults(cmd *cobra.Command, results []*compareResult) error {
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(results, output.IsPretty(cmd))
+	}
+	var rows [][]string
+	for _, r := range results {
+		latency := "-"
+		if r.Latency > 0 {
+			latency = fmt.Sprintf("%.1fs", r.Latency)
+		}
+		first := firstLine(r.Error)
+		if len(r.Outputs) > 0 {
+			first = r.Outputs[0]
+		}
+		rows = append(rows, []string{r.ModelID, r.Status, latency, compareCost(r.Estimate), first})
+		for i := 1; i < len(r.Outputs); i++ {
+			rows = append(rows, []string{"", "", "", "", r.Outputs[i]})
+		}
+	}
+	output.PrintTable([]string{"MODEL", "STATUS", "LATENCY", "EST. COST", "OUTPUT"}, rows)
+	return nil
+}
+
+// printCompareDryRun prints each model's payload and estimated cost.
+func printCompareDryRun(cmd *cobra.Command, results []*compareResult) error {
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(struct {
+			DryRun bool             `json:"dry_run"`
+			Models []*compareResult `json:"models"`
+		}{true, results}, output.IsPretty(cmd))
+	}
+	rows := make([][]string, 0, len(results))
+	for _, r := range results {
+		data, err := json.Marshal(r.Payload)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, []string{r.ModelID, compareCost(r.Estimate), string(data)})
+	}
+	output.PrintTable([]string{"MODEL", "EST. COST", "PAYLOAD"}, rows)
+	fmt.Println("\nDry run: nothing was submitted.")
+	return nil
+}
+
+func compareCost(e *costEstimate) string {
+	if e == nil || e.Total == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.4f %s", *e.Total, e.Currency)
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/the20100/fal-cli/internal/catalog"
+	"github.com/the20100/fal-cli/internal/jobs"
+	"github.com/the20100/fal-cli/internal/schema"
+)
+
+// Completion only reads local state: the catalog synced by "fal models
+// sync", the job ledger and cached schemas. It never calls the API, so a
+// slow or missing network cannot stall the shell.
+
+// maxRequestCompletions bounds how many recent request IDs are offered.
+const maxRequestCompletions = 50
+
+// isCompletionCommand reports whether cmd is cobra's completion machinery:
+// the hidden __complete commands or "completion <shell>".
+func isCompletionCommand(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	if p := cmd.Parent(); p != nil && p.Name() == "completion" {
+		cmd = p
+	}
+	return cmd.Name() == "completion" && cmd.Parent() == rootCmd
+}
+
+// completeModelID completes a single model ID argument.
+func completeModelID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return modelCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeModelIDs completes any number of model ID arguments, skipping
+// those already given.
+func completeModelIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return modelCompletions(toComplete, args), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeQueueArgs completes "[model-id] <request-id>": recent request IDs
+// first (or, when none match, model IDs), then the request IDs of the model
+// given as first argument.
+func completeQueueArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		if ids := requestCompletions(toComplete, ""); len(ids) > 0 {
+			return ids, cobra.ShellCompDirectiveNoFileComp
+		}
+		return modelCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+	case 1:
+		if _, err := jobs.Find(args[0]); err == nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp // already a request ID
+		}
+		return requestCompletions(toComplete, args[0]), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRequestID completes a single request ID argument.
+func completeRequestID(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return requestCompletions(toComplete, ""), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRunArgs completes run's raw arguments (run parses its own flags,
+// so cobra hands them over unparsed): the model ID, then the model's
+// parameter flags and enum values from its cached schema.
+func completeRunArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	modelID, _, err := scanRunArgs(cmd, args)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault // e.g. the value of --input
+	}
+	if modelID == "" && !strings.HasPrefix(toComplete, "-") {
+		return modelCompletions(toComplete, nil), cobra.ShellCompDirectiveNoFileComp
+	}
+
+	var ep *schema.Endpoint
+	if modelID != "" {
+		if raw, _, err := schema.Load(modelID); err == nil {
+			ep, _ = schema.Parse(modelID, raw)
+		}
+	}
+
+	if strings.HasPrefix(toComplete, "-") {
+		var out []string
+		if ep != nil {
+			for _, f := range ep.Fields(ep.Input) {
+				name := "--" + strings.ReplaceAll(f.Name, "_", "-")
+				if cmd.Flags().Lookup(name[2:]) != nil || !strings.HasPrefix(name, toComplete) {
+					continue
+				}
+				var desc string
+				if f.Schema != nil {
+					desc = firstLine(f.Schema.Description)
+				}
+				out = append(out, name+"\t"+desc)
+			}
+		}
+		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			if name := "--" + f.Name; !f.Hidden && f.Deprecated == "" && strings.HasPrefix(name, toComplete) {
+				out = append(out, name+"\t"+f.Usage)
+			}
+		})
+		return out, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	// The value of a parameter flag with a fixed set of values.
+	if ep != nil && len(args) > 0 {
+		last := args[len(args)-1]
+		if strings.HasPrefix(last, "--") && !strings.Contains(last, "=") {
+			name := strings.ReplaceAll(strings.TrimPrefix(last, "--"), "-", "_")
+			for _, f := range ep.Fields(ep.Input) {
+				if f.Name == name {
+					var out []string
+					for _, v := range paramEnum(ep, f.Schema) {
+						if strings.HasPrefix(v, toComplete) {
+							out = append(out, v)
+						}
+					}
+					return out, cobra.ShellCompDirectiveNoFileComp
+				}
+			}
+		}
+	}
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+// modelCompletions returns the known model IDs starting with prefix, with
+// their display name as description. Models come from the local catalog
+// and, so that recently used models complete even without a sync, from the
+// job ledger.
+func modelCompletions(prefix string, exclude []string) []string {
+	skip := make(map[string]bool, len(exclude))
+	for _, id := range exclude {
+		skip[id] = true
+	}
+
+	desc := map[string]string{}
+	if c, err := catalog.Load(); err == nil {
+		for _, m := range c.Models {
+			desc[m.EndpointID] = m.Metadata.DisplayName
+		}
+	}
+	if all, err := jobs.List(); err == nil {
+		for _, j := range all {
+			if _, ok := desc[j.ModelID]; !ok && j.ModelID != "" {
+				desc[j.ModelID] = "recently used"
+			}
+		}
+	}
+
+	var out []string
+	for id, d := range desc {
+		if !skip[id] && strings.HasPrefix(id, prefix) {
+			out = append(out, id+"\t"+d)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// requestCompletions returns the most recent request IDs from the job
+// ledger starting with prefix (optionally only those of modelID), with
+// their model, status and submission time as description.
+func requestCompletions(prefix, modelID string) []string {
+	all, err := jobs.List()
+	if err != nil {
+		return nil
+	}
+	var out []string
+	for _, j := range all {
+		if modelID != "" && j.ModelID != modelID {
+			continue
+		}
+		if !strings.HasPrefix(j.RequestID, prefix) {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s\t%s · %s · %s",
+			j.RequestID, j.ModelID, j.Status, j.SubmittedAt.Local().Format("2006-01-02 15:04")))
+		if len(out) == maxRequestCompletions {
+			break
+		}
+	}
+	return out
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/catalog"
+	"github.com/the20100/fal-cli/internal/output"
+)
+
+// costEstimate is the expected price of one request: the model's unit price
+// times the number of units, adjusted by the billing rules known for the
+// model. Total is nil when the number of units cannot be known in advance
+// (e.g. models billed per second of output).
+type costEstimate struct {
+	UnitPrice float64      `json:"unit_price"`
+	Unit      string       `json:"unit"`
+	Currency  string       `json:"currency"`
+	Quantity  float64      `json:"quantity,omitempty"`
+	Factors   []costFactor `json:"factors,omitempty"`
+	Extras    []costExtra  `json:"extras,omitempty"`
+	Total     *float64     `json:"total,omitempty"`
+	Note      string       `json:"note,omitempty"`
+}
+
+// costFactor multiplies the base price, e.g. 4K output billed at 2x.
+type costFactor struct {
+	Name   string  `json:"name"`
+	Factor float64 `json:"factor"`
+}
+
+// costExtra is a surcharge added to the base price.
+type costExtra struct {
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+// costRule applies a model's billing adjustments to an estimate, given the
+// payload and the number of outputs requested.
+type costRule func(e *costEstimate, payload map[string]any, outputs float64)
+
+// webSearchPrice is the surcharge per image for web search grounding.
+const webSearchPrice = 0.015
+
+// costRules holds the billing adjustments of specific models, keyed by
+// endpoint ID prefix; the longest matching prefix applies.
+var costRules = map[string]costRule{
+	"fal-ai/nano-banana": bananaCost,
+	"openai/gpt-image":   gptImageCost,
+}
+
+// bananaCost: 4K output is billed at twice the unit price, and web search
+// grounding adds a flat fee per image.
+func bananaCost(e *costEstimate, payload map[string]any, outputs float64) {
+	if res, _ := payload["resolution"].(string); strings.EqualFold(res, "4K") {
+		e.Factors = append(e.Factors, costFactor{Name: "4K resolution", Factor: 2})
+	}
+	if on, _ := payload["enable_web_search"].(bool); on {
+		e.Extras = append(e.Extras, costExtra{Name: "web search", Amount: webSearchPrice * outputs})
+	}
+}
+
+// gptImageCost: GPT Image bills by tokens, which depend on quality and
+// size, so the catalog price is only indicative.
+func gptImageCost(e *costEstimate, payload map[string]any, outputs float64) {
+	e.Note = "actual price varies with quality and image size"
+}
+
+// estimateCost prices payload on modelID (see fetchPrice for where the
+// unit price comes from).
+func estimateCost(ctx context.Context, modelID string, payload map[string]any) (*costEstimate, error) {
+	price, err := lookupPrice(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+	e := &costEstimate{UnitPrice: price.UnitPrice, Unit: price.Unit, Currency: strings.ToUpper(price.Currency)}
+
+	outputs := 1.0
+	if n, ok := payload["num_images"]; ok {
+		if f, ok := toFloat(n); ok && f > 0 {
+			outputs = f
+		}
+	}
+
+	unit := strings.ToLower(strings.TrimSpace(price.Unit))
+	switch {
+	case strings.HasPrefix(unit, "image"):
+		e.Quantity = outputs
+	case unit == "request" || unit == "call" || unit == "generation" || strings.HasPrefix(unit, "video"):
+		e.Quantity = 1
+	}
+
+	if rule := costRuleFor(modelID); rule != nil {
+		rule(e, payload, outputs)
+	}
+
+	if e.Quantity > 0 {
+		total := e.UnitPrice * e.Quantity
+		for _, f := range e.Factors {
+			total *= f.Factor
+		}
+		for _, x := range e.Extras {
+			total += x.Amount
+		}
+		e.Total = &total
+	} else if e.Note == "" {
+		e.Note = fmt.Sprintf("billed per %s; the total depends on the output", price.Unit)
+	}
+	return e, nil
+}
+
+func costRuleFor(modelID string) costRule {
+	var best string
+	for prefix := range costRules {
+		if strings.HasPrefix(modelID, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	return costRules[best]
+}
+
+// priceMemo holds the prices looked up by this process. Batches, sweeps
+// and bulk edits estimate every line, so each model is only looked up once
+// instead of re-reading the price cache and the catalog per line.
+var priceMemo = struct {
+	sync.Mutex
+	m map[string]memoPrice
+}{m: map[string]memoPrice{}}
+
+type memoPrice struct {
+	price *api.ModelPrice
+	err   error
+}
+
+// lookupPrice returns the price of modelID, looked up once per process (see
+// fetchPrice).
+func lookupPrice(ctx context.Context, modelID string) (*api.ModelPrice, error) {
+	priceMemo.Lock()
+	defer priceMemo.Unlock()
+	if r, ok := priceMemo.m[modelID]; ok {
+		return r.price, r.err
+	}
+	price, err := fetchPrice(ctx, modelID)
+	if ctx.Err() == nil {
+		priceMemo.m[modelID] = memoPrice{price, err}
+	}
+	return price, err
+}
+
+// fetchPrice returns the price of modelID: from the local cache while it is
+// fresh, otherwise from the pricing API (caching it), falling back to a
+// stale cached price when the API cannot be reached.
+func fetchPrice(ctx context.Context, modelID string) (*api.ModelPrice, error) {
+	cached, fetchedAt, ok := catalog.CachedPrice(modelID)
+	if ok && time.Since(fetchedAt) < catalog.PriceMaxAge {
+		return &cached, nil
+	}
+
+	resp, err := client.GetModelPricing(ctx, []string{modelID})
+	if err == nil {
+		for _, p := range resp.Prices {
+			if p.EndpointID == modelID {
+				if err := catalog.StorePrice(p); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: could not cache price: %s\n", err)
+				}
+				return &p, nil
+			}
+		}
+		return nil, fmt.Errorf("no price listed for %s", modelID)
+	}
+	if ok && ctx.Err() == nil {
+		return &cached, nil
+	}
+	return nil, err
+}
+
+// String describes the estimate, e.g.
+// "0.3800 USD (0.0400 USD/image × 4 × 2 (4K resolution) + 0.0600 web search)".
+func (e *costEstimate) String() string {
+	base := output.FormatPrice(e.UnitPrice, e.Unit, e.Currency)
+	if e.Total == nil {
+		return fmt.Sprintf("%s (%s)", base, e.Note)
+	}
+	var b strings.Builder
+	b.WriteString(base)
+	if e.Quantity != 1 {
+		fmt.Fprintf(&b, " × %s", formatNumber(e.Quantity))
+	}
+	for _, f := range e.Factors {
+		fmt.Fprintf(&b, " × %s (%s)", formatNumber(f.Factor), f.Name)
+	}
+	for _, x := range e.Extras {
+		fmt.Fprintf(&b, " + %.4f %s", x.Amount, x.Name)
+	}
+	s := fmt.Sprintf("%.4f %s (%s)", *e.Total, e.Currency, b.String())
+	if e.Note != "" {
+		s += "; " + e.Note
+	}
+	return s
+}
+
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/download"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/provenance"
+)
+
+// downloadOptions holds the flags for saving result files to disk.
+type downloadOptions struct {
+	dir       string
+	template  string
+	overwrite bool
+	sidecar   bool
+}
+
+// addDownloadFlags registers --download/-o, --name-template, --overwrite
+// and --sidecar on cmd.
+func addDownloadFlags(cmd *cobra.Command, o *downloadOptions) {
+	cmd.Flags().StringVarP(&o.dir, "download", "o", "",
+		"Download every output file into this directory")
+	cmd.Flags().StringVar(&o.template, "name-template", download.DefaultTemplate,
+		"File name template for --download, \"/\" for subfolders: {model} {seed} {index} {ext} {name} {request_id}")
+	cmd.Flags().BoolVar(&o.overwrite, "overwrite", false,
+		"Overwrite existing files with --download (default: add a numeric suffix)")
+	cmd.Flags().BoolVar(&o.sidecar, "sidecar", false,
+		"With --download, also write <file>.json holding the request payload and response")
+}
+
+// saveOutputs downloads every media file in result when --download is set.
+// PNG and JPEG files get the prompt, model, seed and request ID embedded
+// (see "fal inspect"). payload may be nil when the request was not
+// submitted by this process and is not in the job ledger.
+// Progress goes to stderr so JSON on stdout stays machine-readable.
+func saveOutputs(ctx context.Context, cmd *cobra.Command, o *downloadOptions, modelID, requestID string, payload map[string]any, result []byte) error {
+	if o.dir == "" {
+		return nil
+	}
+
+	files := api.FindFiles(result)
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "No output files to download.")
+		return nil
+	}
+
+	seed := resultSeed(result)
+	if seed == "" && payload != nil && payload["seed"] != nil {
+		seed = fmt.Sprint(payload["seed"])
+	}
+
+	fmt.Fprintf(os.Stderr, "Downloading %d file(s) to %s...\n", len(files), o.dir)
+	results := download.All(ctx, files, download.Options{
+		Dir:       o.dir,
+		Template:  o.template,
+		Overwrite: o.overwrite,
+		Model:     modelID,
+		Seed:      seed,
+		RequestID: requestID,
+	})
+
+	info := provenance.Info{
+		Model:     modelID,
+		Seed:      seed,
+		RequestID: requestID,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if prompt, ok := payload["prompt"].(string); ok {
+		info.Prompt = prompt
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "  ✗ %s: %s\n", output.Truncate(r.URL, 80), r.Err)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "  ✓ %s\n", r.Path)
+
+		if err := provenance.Embed(r.Path, info); err != nil && !errors.Is(err, provenance.ErrUnsupported) {
+			fmt.Fprintf(os.Stderr, "    Warning: could not embed provenance: %s\n", err)
+		}
+		if o.sidecar {
+			if err := writeSidecar(r.Path, r.URL, info, payload, result); err != nil {
+				fmt.Fprintf(os.Stderr, "    Warning: could not write sidecar: %s\n", err)
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d download(s) failed", failed, len(results))
+	}
+	return nil
+}
+
+// sidecar is the JSON written next to a downloaded file with --sidecar.
+type sidecar struct {
+	Provenance provenance.Info `json:"provenance"`
+	SourceURL  string          `json:"source_url"`
+	Payload    map[string]any  `json:"payload,omitempty"`
+	Response   json.RawMessage `json:"response"`
+}
+
+func writeSidecar(path, sourceURL string, info provenance.Info, payload map[string]any, result []byte) error {
+	info.Tool = provenance.Tool
+	if !strings.HasPrefix(sourceURL, "http") {
+		sourceURL = "" // inline data URL, already saved as the file itself
+	}
+	data, err := json.MarshalIndent(sidecar{
+		Provenance: info,
+		SourceURL:  sourceURL,
+		Payload:    payload,
+		Response:   result,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(provenance.SidecarPath(path), append(data, '\n'), 0644)
+}
+
+// resultSeed returns the "seed" reported in a model result, formatted
+// without exponent, or "" when there is none.
+func resultSeed(result []byte) string {
+	var v struct {
+		Seed json.Number `json:"seed"`
+	}
+	dec := json.NewDecoder(bytes.NewReader(result))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return ""
+	}
+	return v.Seed.String()
+}
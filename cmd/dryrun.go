@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/usage"
+)
+
+// dryRunResult is the JSON output of --dry-run.
+type dryRunResult struct {
+	DryRun        bool           `json:"dry_run"`
+	ModelID       string         `json:"model_id"`
+	Endpoint      string         `json:"endpoint"`
+	Mode          string         `json:"mode"` // "queue" or "sync"
+	Payload       map[string]any `json:"payload"`
+	Estimate      *costEstimate  `json:"estimate"`
+	EstimateError string         `json:"estimate_error,omitempty"`
+	BudgetError   string         `json:"budget_error,omitempty"`
+}
+
+// printDryRun shows what submit would send, and what it would likely cost,
+// without submitting it.
+func printDryRun(cmd *cobra.Command, modelID string, payload map[string]any, o *submitOptions) error {
+	res := dryRunResult{DryRun: true, ModelID: modelID, Mode: "sync", Payload: payload}
+	base := client.Endpoints().Run
+	if o.useQueue() {
+		res.Mode = "queue"
+		base = client.Endpoints().Queue
+	}
+	res.Endpoint = base + "/" + strings.TrimPrefix(modelID, "/")
+
+	est, err := estimateCost(cmd.Context(), modelID, payload)
+	if err != nil {
+		if cmd.Context().Err() != nil {
+			return err
+		}
+		res.EstimateError = err.Error()
+	}
+	res.Estimate = est
+	if est != nil && est.Total != nil {
+		b := currentBudget()
+		if err := usage.Check(usage.Entry{At: time.Now(), ModelID: modelID, Cost: *est.Total}, &b); err != nil {
+			res.BudgetError = err.Error()
+		}
+	}
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(res, output.IsPretty(cmd))
+	}
+
+	estimate := "unavailable (" + res.EstimateError + ")"
+	if est != nil {
+		estimate = est.String()
+	}
+	output.PrintKeyValue([][]string{
+		{"MODEL", modelID},
+		{"ENDPOINT", "POST " + res.Endpoint},
+		{"MODE", res.Mode},
+		{"ESTIMATE", estimate},
+		{"BUDGET", res.BudgetError},
+	})
+	data, err := json.MarshalIndent(payload, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nPayload:\n%s\n\nDry run: nothing was submitted.\n", data)
+	return nil
+}
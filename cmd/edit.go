@@ -12,19 +12,12 @@ import (
 	"fmt"
 	"mime"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
 )
 
-// execCommand runs a command and returns its combined output.
-func execCommand(name string, args ...string) (string, error) {
-	out, err := exec.Command(name, args...).CombinedOutput()
-	return string(out), err
-}
-
 var (
 	editImages       []string
 	editFiles        []string
@@ -36,10 +29,9 @@ var (
 	editSeed         int64
 	editWebSearch    bool
 	editGoogleSearch bool
-	editQueue        bool
-	editLogs         bool
-	editR2Bucket     string
-	editR2Domain     string
+	editSubmit       submitOptions
+	editUpload       uploadOptions
+	editBulk         bulkEditOptions
 )
 
 var editCmd = &cobra.Command{
@@ -48,16 +40,25 @@ var editCmd = &cobra.Command{
 	Long: `Shortcut for fal-ai/nano-banana-2/edit — state-of-the-art image editing model.
 
 Provide image sources via --image (URL) and/or --file (local absolute path).
-Local files are encoded as base64 data URIs by default.
-Use --r2-bucket + --r2-domain to upload to R2 instead (better for large files).
+Local files up to 1 MB are sent as base64 data URIs; larger ones are
+uploaded to fal storage first, with progress shown on a terminal. Force
+one or the other with --upload-mode fal|datauri, or upload to your own
+S3-compatible bucket (R2, S3, MinIO) with --upload-mode s3 (see "fal storage").
 Both flags are repeatable and can be combined.
 
+With --dir and --out, every image under a directory is edited as its own
+queued request, --concurrency at a time; outputs keep the relative paths of
+their inputs, and images that already have an output are skipped. --image
+and --file sources are then added to every request.
+
 Examples:
   fal edit-banana "make it night time" --image https://example.com/photo.jpg
   fal edit-banana "make it night time" --file /path/to/photo.jpg
   fal edit-banana "add snow" --file /path/to/city.jpg --aspect 16:9
-  fal edit-banana "add snow" --file /path/to/city.jpg --r2-bucket my-pub --r2-domain pub.example.com
-  fal edit-banana "remove background" --image https://... --file /path/to/other.jpg --num 2`,
+  fal edit-banana "add snow" --file /path/to/city.jpg --upload-mode fal
+  fal edit-banana "add snow" --file /path/to/city.jpg --upload-mode s3
+  fal edit-banana "remove background" --image https://... --file /path/to/other.jpg --num 2
+  fal edit-banana "make it night time" --dir ./in --glob '*.jpg' --out ./out -c 8`,
 	Args: cobra.ExactArgs(1),
 	RunE: runEdit,
 }
@@ -83,55 +84,27 @@ func init() {
 		"Enable web search grounding (+$0.015/image)")
 	editCmd.Flags().BoolVar(&editGoogleSearch, "google-search", false,
 		"Enable Google search grounding")
-	editCmd.Flags().BoolVar(&editQueue, "queue", false,
-		"Submit via queue instead of sync")
-	editCmd.Flags().BoolVar(&editLogs, "logs", false,
-		"Show model logs while polling queue (implies --queue)")
-	editCmd.Flags().StringVar(&editR2Bucket, "r2-bucket", "",
-		"Upload files to this R2 bucket instead of encoding as base64 (requires r2 CLI)")
-	editCmd.Flags().StringVar(&editR2Domain, "r2-domain", "",
-		"Public domain for R2 bucket (e.g. pub.example.com); required with --r2-bucket")
+	addSubmitFlags(editCmd, &editSubmit)
+	addUploadFlags(editCmd, &editUpload, uploadModeAuto)
+	addBulkEditFlags(editCmd, &editBulk)
 	rootCmd.AddCommand(editCmd)
 }
 
-// resolveImageSources converts local files to data URIs (base64) or uploads
-// them to R2 when r2Bucket is set, then merges with any remote URLs.
-func resolveImageSources(cmd *cobra.Command, urls []string, files []string, r2Bucket, r2Domain string) ([]string, error) {
+// resolveImageSources merges the remote URLs with the local files, which
+// are checked and passed as @file: references for submit to upload.
+func resolveImageSources(urls []string, files []string) ([]string, error) {
 	if len(urls) == 0 && len(files) == 0 {
 		return nil, fmt.Errorf("at least one --image <url> or --file <path> is required")
 	}
 
 	result := make([]string, 0, len(urls)+len(files))
 	result = append(result, urls...)
-
-	if len(files) > 0 {
-		if r2Bucket != "" {
-			// R2 upload path
-			if r2Domain == "" {
-				return nil, fmt.Errorf("--r2-domain is required when using --r2-bucket")
-			}
-			fmt.Fprintf(cmd.ErrOrStderr(), "Uploading %d file(s) to R2 bucket %q...\n", len(files), r2Bucket)
-			for _, path := range files {
-				publicURL, err := uploadToR2(cmd, path, r2Bucket, r2Domain)
-				if err != nil {
-					return nil, fmt.Errorf("R2 upload failed for %s: %w", path, err)
-				}
-				result = append(result, publicURL)
-			}
-		} else {
-			// Base64 data URI path (default)
-			fmt.Fprintf(cmd.ErrOrStderr(), "Encoding %d file(s) as base64...\n", len(files))
-			for _, path := range files {
-				dataURI, err := fileToDataURI(path)
-				if err != nil {
-					return nil, fmt.Errorf("encoding failed for %s: %w", path, err)
-				}
-				fmt.Fprintf(cmd.ErrOrStderr(), "  ✓ %s (%s)\n", filepath.Base(path), mimeFromPath(path))
-				result = append(result, dataURI)
-			}
+	for _, path := range files {
+		if _, err := os.Stat(path); err != nil {
+			return nil, err
 		}
+		result = append(result, fileRefPrefix+path)
 	}
-
 	return result, nil
 }
 
@@ -156,30 +129,24 @@ func fileToDataURI(path string) (string, error) {
 	return fmt.Sprintf("data:%s;base64,%s", mimeType, encoded), nil
 }
 
-// uploadToR2 uploads a file to an R2 bucket via the r2 CLI and returns its public URL.
-func uploadToR2(cmd *cobra.Command, localPath, bucket, domain string) (string, error) {
-	key := "fal-tmp/" + filepath.Base(localPath)
-	fmt.Fprintf(cmd.ErrOrStderr(), "  uploading %s → %s/%s\n", filepath.Base(localPath), bucket, key)
-
-	out, err := execCommand("r2", "objects", "put", key, "--bucket", bucket, "--file", localPath)
-	if err != nil {
-		return "", fmt.Errorf("r2 put: %s: %w", strings.TrimSpace(out), err)
-	}
-
-	domain = strings.TrimRight(domain, "/")
-	publicURL := fmt.Sprintf("https://%s/%s", domain, key)
-	fmt.Fprintf(cmd.ErrOrStderr(), "  → %s\n", publicURL)
-	return publicURL, nil
-}
-
 func runEdit(cmd *cobra.Command, args []string) error {
 	prompt := args[0]
+	modelID := "fal-ai/nano-banana-2/edit"
 
-	imageURLs, err := resolveImageSources(cmd, editImages, editFiles, editR2Bucket, editR2Domain)
+	payload := func(imageURLs []string) map[string]any { return editPayload(prompt, imageURLs) }
+	if editBulk.dir != "" || editBulk.out != "" {
+		return runBulkEdit(cmd, modelID, &editBulk, &editSubmit, editUpload, editImages, editFiles, editNum, payload)
+	}
+
+	imageURLs, err := resolveImageSources(editImages, editFiles)
 	if err != nil {
 		return err
 	}
+	return submit(cmd, modelID, payload(imageURLs), &editSubmit, &editUpload)
+}
 
+// editPayload builds the nano-banana-2/edit request from the flags.
+func editPayload(prompt string, imageURLs []string) map[string]any {
 	payload := map[string]any{
 		"prompt":           prompt,
 		"image_urls":       imageURLs,
@@ -199,11 +166,5 @@ func runEdit(cmd *cobra.Command, args []string) error {
 	if editGoogleSearch {
 		payload["enable_google_search"] = true
 	}
-
-	modelID := "fal-ai/nano-banana-2/edit"
-
-	if editQueue || editLogs {
-		return runViaQueue(cmd, modelID, payload, editLogs)
-	}
-	return runViaSync(cmd, modelID, payload)
+	return payload
 }
@@ -16,17 +16,16 @@ import (
 )
 
 var (
-	gptEditImages       []string
-	gptEditFiles        []string
-	gptEditMask         string
-	gptEditQuality      string
-	gptEditResolution   string
-	gptEditNum          int
-	gptEditFormat       string
-	gptEditQueue        bool
-	gptEditLogs         bool
-	gptEditR2Bucket     string
-	gptEditR2Domain     string
+	gptEditImages     []string
+	gptEditFiles      []string
+	gptEditMask       string
+	gptEditQuality    string
+	gptEditResolution string
+	gptEditNum        int
+	gptEditFormat     string
+	gptEditSubmit     submitOptions
+	gptEditUpload     uploadOptions
+	gptEditBulk       bulkEditOptions
 )
 
 var gptEditCmd = &cobra.Command{
@@ -35,10 +34,17 @@ var gptEditCmd = &cobra.Command{
 	Long: `Shortcut for openai/gpt-image-2/edit — high-quality image editing model.
 
 Provide image sources via --image (URL) and/or --file (local absolute path).
-Local files are encoded as base64 data URIs by default.
-Use --r2-bucket + --r2-domain to upload to R2 instead (better for large files).
+Local files up to 1 MB are sent as base64 data URIs; larger ones are
+uploaded to fal storage first, with progress shown on a terminal. Force
+one or the other with --upload-mode fal|datauri, or upload to your own
+S3-compatible bucket (R2, S3, MinIO) with --upload-mode s3 (see "fal storage").
 Both flags are repeatable and can be combined.
 
+With --dir and --out, every image under a directory is edited as its own
+queued request, --concurrency at a time; outputs keep the relative paths of
+their inputs, and images that already have an output are skipped. --image
+and --file sources are then added to every request.
+
 Quality/resolution recommendations:
   quality low    → use 4K for best results
   quality medium → 2K or 4K both work well (default: 2K)
@@ -48,9 +54,10 @@ Examples:
   fal edit "make it night time" --image https://example.com/photo.jpg --queue --json
   fal edit "make it night time" --file /path/to/photo.jpg --queue --json
   fal edit "add snow" --file /path/to/city.jpg --resolution 4K --queue --json
-  fal edit "add snow" --file /path/to/city.jpg --r2-bucket my-pub --r2-domain pub.example.com --queue --json
+  fal edit "add snow" --file /path/to/city.jpg --upload-mode s3 --queue --json
   fal edit "remove background" --image https://... --file /path/to/other.jpg --num 2 --queue --json
-  fal edit "detailed retouch" --quality low --resolution 4K --file /path/to/photo.jpg --queue --json`,
+  fal edit "detailed retouch" --quality low --resolution 4K --file /path/to/photo.jpg --queue --json
+  fal edit "remove the background" --dir ./in --glob '*.jpg' --out ./out`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGptEdit,
 }
@@ -70,25 +77,30 @@ func init() {
 		"Number of images to generate (1-4)")
 	gptEditCmd.Flags().StringVar(&gptEditFormat, "format", "png",
 		"Output format: jpeg, png, webp")
-	gptEditCmd.Flags().BoolVar(&gptEditQueue, "queue", false,
-		"Submit via queue instead of sync")
-	gptEditCmd.Flags().BoolVar(&gptEditLogs, "logs", false,
-		"Show model logs while polling queue (implies --queue)")
-	gptEditCmd.Flags().StringVar(&gptEditR2Bucket, "r2-bucket", "",
-		"Upload files to this R2 bucket instead of encoding as base64 (requires r2 CLI)")
-	gptEditCmd.Flags().StringVar(&gptEditR2Domain, "r2-domain", "",
-		"Public domain for R2 bucket (e.g. pub.example.com); required with --r2-bucket")
+	addSubmitFlags(gptEditCmd, &gptEditSubmit)
+	addUploadFlags(gptEditCmd, &gptEditUpload, uploadModeAuto)
+	addBulkEditFlags(gptEditCmd, &gptEditBulk)
 	rootCmd.AddCommand(gptEditCmd)
 }
 
 func runGptEdit(cmd *cobra.Command, args []string) error {
 	prompt := args[0]
+	modelID := "openai/gpt-image-2/edit"
 
-	imageURLs, err := resolveImageSources(cmd, gptEditImages, gptEditFiles, gptEditR2Bucket, gptEditR2Domain)
+	payload := func(imageURLs []string) map[string]any { return gptEditPayload(prompt, imageURLs) }
+	if gptEditBulk.dir != "" || gptEditBulk.out != "" {
+		return runBulkEdit(cmd, modelID, &gptEditBulk, &gptEditSubmit, gptEditUpload, gptEditImages, gptEditFiles, gptEditNum, payload)
+	}
+
+	imageURLs, err := resolveImageSources(gptEditImages, gptEditFiles)
 	if err != nil {
 		return err
 	}
+	return submit(cmd, modelID, payload(imageURLs), &gptEditSubmit, &gptEditUpload)
+}
 
+// gptEditPayload builds the GPT Image 2 edit request from the flags.
+func gptEditPayload(prompt string, imageURLs []string) map[string]any {
 	payload := map[string]any{
 		"prompt":        prompt,
 		"image_urls":    imageURLs,
@@ -101,11 +113,5 @@ func runGptEdit(cmd *cobra.Command, args []string) error {
 	if gptEditMask != "" {
 		payload["mask_url"] = gptEditMask
 	}
-
-	modelID := "openai/gpt-image-2/edit"
-
-	if gptEditQueue || gptEditLogs {
-		return runViaQueue(cmd, modelID, payload, gptEditLogs)
-	}
-	return runViaSync(cmd, modelID, payload)
+	return payload
 }
@@ -19,8 +19,8 @@ var (
 	generateSeed         int64
 	generateWebSearch    bool
 	generateGoogleSearch bool
-	generateQueue        bool
-	generateLogs         bool
+	generateSubmit       submitOptions
+	generateSweep        sweepOptions
 )
 
 var generateCmd = &cobra.Command{
@@ -32,7 +32,8 @@ Examples:
   fal generate-banana "a cat wearing a hat"
   fal generate-banana "golden gate bridge at sunset" --aspect 16:9
   fal generate-banana "portrait of a woman" --resolution 2K --num 2
-  fal generate-banana "futuristic city" --format webp --queue`,
+  fal generate-banana "futuristic city" --format webp --queue
+  fal generate-banana "a cat" --vary seed=1..4 --vary resolution=1K,2K -o ./cats`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGenerate,
 }
@@ -54,16 +55,25 @@ func init() {
 		"Enable web search grounding (+$0.015/image)")
 	generateCmd.Flags().BoolVar(&generateGoogleSearch, "google-search", false,
 		"Enable Google search grounding")
-	generateCmd.Flags().BoolVar(&generateQueue, "queue", false,
-		"Submit via queue instead of sync")
-	generateCmd.Flags().BoolVar(&generateLogs, "logs", false,
-		"Show model logs while polling queue (implies --queue)")
+	addSubmitFlags(generateCmd, &generateSubmit)
+	addSweepFlags(generateCmd, &generateSweep)
 	rootCmd.AddCommand(generateCmd)
 }
 
 func runGenerate(cmd *cobra.Command, args []string) error {
 	prompt := args[0]
+	modelID := "fal-ai/nano-banana-2"
+
+	if len(generateSweep.vary) > 0 {
+		return runShortcutSweep(cmd, modelID, &generateSweep, &generateSubmit, func() map[string]any {
+			return generatePayload(prompt)
+		})
+	}
+	return submit(cmd, modelID, generatePayload(prompt), &generateSubmit, nil)
+}
 
+// generatePayload builds the nano-banana-2 request from the flags.
+func generatePayload(prompt string) map[string]any {
 	payload := map[string]any{
 		"prompt":           prompt,
 		"aspect_ratio":     generateAspect,
@@ -82,11 +92,5 @@ func runGenerate(cmd *cobra.Command, args []string) error {
 	if generateGoogleSearch {
 		payload["enable_google_search"] = true
 	}
-
-	modelID := "fal-ai/nano-banana-2"
-
-	if generateQueue || generateLogs {
-		return runViaQueue(cmd, modelID, payload, generateLogs)
-	}
-	return runViaSync(cmd, modelID, payload)
+	return payload
 }
@@ -19,8 +19,8 @@ var (
 	gptGenerateResolution string
 	gptGenerateNum        int
 	gptGenerateFormat     string
-	gptGenerateQueue      bool
-	gptGenerateLogs       bool
+	gptGenerateSubmit     submitOptions
+	gptGenerateSweep      sweepOptions
 )
 
 var gptGenerateCmd = &cobra.Command{
@@ -38,7 +38,8 @@ Examples:
   fal generate "golden gate bridge at sunset" --quality high --queue --json
   fal generate "portrait of a woman" --resolution 4K --num 2 --queue --json
   fal generate "futuristic city" --format webp --queue --json
-  fal generate "detailed artwork" --quality low --resolution 4K --queue --json`,
+  fal generate "detailed artwork" --quality low --resolution 4K --queue --json
+  fal generate "a lighthouse" --vary quality=low,medium,high --vary resolution=2K,4K -o ./sweep`,
 	Args: cobra.ExactArgs(1),
 	RunE: runGptGenerate,
 }
@@ -52,10 +53,8 @@ func init() {
 		"Number of images to generate (1-4)")
 	gptGenerateCmd.Flags().StringVar(&gptGenerateFormat, "format", "png",
 		"Output format: jpeg, png, webp")
-	gptGenerateCmd.Flags().BoolVar(&gptGenerateQueue, "queue", false,
-		"Submit via queue instead of sync")
-	gptGenerateCmd.Flags().BoolVar(&gptGenerateLogs, "logs", false,
-		"Show model logs while polling queue (implies --queue)")
+	addSubmitFlags(gptGenerateCmd, &gptGenerateSubmit)
+	addSweepFlags(gptGenerateCmd, &gptGenerateSweep)
 	rootCmd.AddCommand(gptGenerateCmd)
 }
 
@@ -70,19 +69,23 @@ func resolutionToImageSize(resolution string) map[string]int {
 
 func runGptGenerate(cmd *cobra.Command, args []string) error {
 	prompt := args[0]
+	modelID := "openai/gpt-image-2"
+
+	if len(gptGenerateSweep.vary) > 0 {
+		return runShortcutSweep(cmd, modelID, &gptGenerateSweep, &gptGenerateSubmit, func() map[string]any {
+			return gptGeneratePayload(prompt)
+		})
+	}
+	return submit(cmd, modelID, gptGeneratePayload(prompt), &gptGenerateSubmit, nil)
+}
 
-	payload := map[string]any{
+// gptGeneratePayload builds the GPT Image 2 request from the flags.
+func gptGeneratePayload(prompt string) map[string]any {
+	return map[string]any{
 		"prompt":        prompt,
 		"image_size":    resolutionToImageSize(gptGenerateResolution),
 		"quality":       gptGenerateQuality,
 		"num_images":    gptGenerateNum,
 		"output_format": gptGenerateFormat,
 	}
-
-	modelID := "openai/gpt-image-2"
-
-	if gptGenerateQueue || gptGenerateLogs {
-		return runViaQueue(cmd, modelID, payload, gptGenerateLogs)
-	}
-	return runViaSync(cmd, modelID, payload)
 }
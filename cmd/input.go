@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parsePayload builds a request payload from --input and --set flags.
+//
+// input may be inline JSON or YAML, "@path" to read a JSON/YAML file, or "-"
+// to read from stdin. Each --set "key.path=value" is then applied on top,
+// with value parsed as JSON when possible (numbers, booleans, null, arrays,
+// objects) and kept as a plain string otherwise.
+func parsePayload(input string, sets []string) (map[string]any, error) {
+	payload := map[string]any{}
+	if input != "" {
+		var err error
+		if payload, err = readInput(input); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applySets(payload, sets); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// applySets applies --set key.path=value overrides to payload.
+func applySets(payload map[string]any, sets []string) error {
+	for _, s := range sets {
+		key, raw, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --set %q: expected key.path=value", s)
+		}
+		if err := setPath(payload, strings.Split(key, "."), parseSetValue(raw)); err != nil {
+			return fmt.Errorf("invalid --set %q: %w", s, err)
+		}
+	}
+	return nil
+}
+
+// readInput resolves the --input source and decodes it.
+func readInput(input string) (map[string]any, error) {
+	var data []byte
+	var source string
+	switch {
+	case input == "-":
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("reading --input from stdin: %w", err)
+		}
+		data, source = b, "stdin"
+	case strings.HasPrefix(input, "@"):
+		path := strings.TrimPrefix(input, "@")
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading --input file: %w", err)
+		}
+		data, source = b, path
+	default:
+		data, source = []byte(input), "--input"
+	}
+
+	payload, err := decodeObject(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", source, err)
+	}
+	return payload, nil
+}
+
+// decodeObject decodes a JSON object, falling back to YAML (a superset of
+// JSON) for anything that is not JSON. The YAML result is normalized
+// through JSON so both formats yield the same Go types.
+func decodeObject(data []byte) (map[string]any, error) {
+	var payload map[string]any
+	jsonErr := json.Unmarshal(data, &payload)
+	if jsonErr == nil {
+		if payload == nil {
+			return nil, fmt.Errorf("expected a JSON object")
+		}
+		return payload, nil
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+		return nil, jsonErr // clearly meant as JSON: report the JSON error
+	}
+
+	var v any
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return nil, fmt.Errorf("not valid JSON or YAML: %w", err)
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported YAML: %w", err)
+	}
+	if err := json.Unmarshal(normalized, &payload); err != nil || payload == nil {
+		return nil, fmt.Errorf("expected a mapping at the top level")
+	}
+	return payload, nil
+}
+
+// parseSetValue interprets a --set value: JSON if it parses, otherwise the
+// raw string (so --set prompt=a cat needs no quoting).
+func parseSetValue(raw string) any {
+	var v any
+	if err := json.Unmarshal([]byte(raw), &v); err == nil {
+		return v
+	}
+	return raw
+}
+
+// setPath assigns value at the dotted path inside root, creating objects
+// as needed. Numeric segments index into existing arrays; an index equal
+// to the array length appends.
+func setPath(root map[string]any, path []string, value any) error {
+	var cur any = root
+	for i, seg := range path {
+		last := i == len(path)-1
+		switch node := cur.(type) {
+		case map[string]any:
+			if last {
+				node[seg] = value
+				return nil
+			}
+			next, ok := node[seg]
+			if !ok || next == nil {
+				next = map[string]any{}
+				node[seg] = next
+			}
+			cur = next
+		case []any:
+			idx, err := strconv.Atoi(seg)
+			if err != nil || idx < 0 || idx > len(node) {
+				return fmt.Errorf("%q is not a valid index into %s (length %d)",
+					seg, strings.Join(path[:i], "."), len(node))
+			}
+			if idx == len(node) {
+				if !last {
+					return fmt.Errorf("cannot append through %s", strings.Join(path[:i+1], "."))
+				}
+				// Appending needs the parent to hold the grown slice.
+				return setPath(root, path[:i], append(node, value))
+			}
+			if last {
+				node[idx] = value
+				return nil
+			}
+			cur = node[idx]
+		default:
+			return fmt.Errorf("%s is not an object", strings.Join(path[:i], "."))
+		}
+	}
+	return nil
+}
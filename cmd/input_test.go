@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		root  string // JSON
+		path  string
+		value any
+		want  string // JSON
+		err   string
+	}{
+		{name: "top-level key", root: `{}`, path: "prompt", value: "a cat", want: `{"prompt":"a cat"}`},
+		{name: "overwrite", root: `{"seed":1}`, path: "seed", value: 2.0, want: `{"seed":2}`},
+		{name: "creates objects", root: `{}`, path: "image_size.width", value: 512.0, want: `{"image_size":{"width":512}}`},
+		{name: "keeps siblings", root: `{"image_size":{"height":768}}`, path: "image_size.width", value: 512.0,
+			want: `{"image_size":{"height":768,"width":512}}`},
+		{name: "replaces null", root: `{"image_size":null}`, path: "image_size.width", value: 512.0,
+			want: `{"image_size":{"width":512}}`},
+		{name: "array index", root: `{"image_urls":["a","b"]}`, path: "image_urls.1", value: "c",
+			want: `{"image_urls":["a","c"]}`},
+		{name: "array append", root: `{"image_urls":["a","b"]}`, path: "image_urls.2", value: "c",
+			want: `{"image_urls":["a","b","c"]}`},
+		{name: "nested append", root: `{"loras":[{"path":"x","scales":[1]}]}`, path: "loras.0.scales.1", value: 2.0,
+			want: `{"loras":[{"path":"x","scales":[1,2]}]}`},
+		{name: "into array element", root: `{"loras":[{"path":"x"}]}`, path: "loras.0.scale", value: 0.5,
+			want: `{"loras":[{"path":"x","scale":0.5}]}`},
+		{name: "index out of range", root: `{"image_urls":["a"]}`, path: "image_urls.3", value: "c",
+			err: `"3" is not a valid index into image_urls (length 1)`},
+		{name: "negative index", root: `{"image_urls":["a"]}`, path: "image_urls.-1", value: "c",
+			err: `"-1" is not a valid index into image_urls`},
+		{name: "non-numeric index", root: `{"image_urls":["a"]}`, path: "image_urls.first", value: "c",
+			err: `"first" is not a valid index into image_urls`},
+		{name: "append through", root: `{"loras":[]}`, path: "loras.0.scale", value: 1.0,
+			err: "cannot append through loras.0"},
+		{name: "through a scalar", root: `{"prompt":"a cat"}`, path: "prompt.text", value: "x",
+			err: "prompt is not an object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var root map[string]any
+			if err := json.Unmarshal([]byte(tt.root), &root); err != nil {
+				t.Fatal(err)
+			}
+			err := setPath(root, strings.Split(tt.path, "."), tt.value)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := json.Marshal(root)
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSetValue(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want any
+	}{
+		{"42", 42.0},
+		{"true", true},
+		{"null", nil},
+		{`["a","b"]`, []any{"a", "b"}},
+		{`{"w":1}`, map[string]any{"w": 1.0}},
+		{`"16:9"`, "16:9"},
+		{"16:9", "16:9"},
+		{"a cat", "a cat"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := parseSetValue(tt.raw); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseSetValue(%q) = %#v, want %#v", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestApplySets(t *testing.T) {
+	payload := map[string]any{"prompt": "a cat"}
+	err := applySets(payload, []string{"num_images=2", "image_size.width=512", "prompt=a dog=cute"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"prompt":     "a dog=cute",
+		"num_images": 2.0,
+		"image_size": map[string]any{"width": 512.0},
+	}
+	if !reflect.DeepEqual(payload, want) {
+		t.Errorf("got %v, want %v", payload, want)
+	}
+
+	for _, s := range []string{"num_images", "=2"} {
+		if err := applySets(map[string]any{}, []string{s}); err == nil || !strings.Contains(err.Error(), "expected key.path=value") {
+			t.Errorf("applySets(%q) error = %v, want an invalid --set error", s, err)
+		}
+	}
+}
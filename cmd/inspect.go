@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/provenance"
+)
+
+var inspectCmd = &cobra.Command{
+	Use:   "inspect <file>",
+	Short: "Show how a downloaded file was generated",
+	Long: `Read the provenance embedded in a file saved with --download: model,
+prompt, seed, request ID and creation time (PNG tEXt/iTXt chunks or JPEG XMP).
+
+If a <file>.json sidecar exists (--sidecar), its request payload is shown too.
+
+Examples:
+  fal inspect out/fal-ai-flux-dev_42_1.png
+  fal inspect out/fal-ai-flux-dev_42_1.png --json`,
+	Args:        cobra.ExactArgs(1),
+	Annotations: map[string]string{annotationOffline: "true"},
+	RunE:        runInspect,
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+}
+
+// inspectResult is the JSON output of fal inspect.
+type inspectResult struct {
+	File       string           `json:"file"`
+	Provenance *provenance.Info `json:"provenance"`
+	Sidecar    string           `json:"sidecar,omitempty"`
+	Payload    map[string]any   `json:"payload,omitempty"`
+}
+
+func runInspect(cmd *cobra.Command, args []string) error {
+	path := args[0]
+	res := inspectResult{File: path}
+
+	info, err := provenance.Read(path)
+	switch {
+	case err == nil:
+		res.Provenance = info
+	case errors.Is(err, provenance.ErrNotFound), errors.Is(err, provenance.ErrUnsupported):
+	default:
+		return err
+	}
+
+	if data, err := os.ReadFile(provenance.SidecarPath(path)); err == nil {
+		var sc sidecar
+		if err := json.Unmarshal(data, &sc); err != nil {
+			return fmt.Errorf("parsing sidecar: %w", err)
+		}
+		res.Sidecar = provenance.SidecarPath(path)
+		res.Payload = sc.Payload
+		if res.Provenance == nil {
+			res.Provenance = &sc.Provenance
+		}
+	}
+
+	if res.Provenance == nil {
+		return fmt.Errorf("%s: %w (and no sidecar %s)", path, provenance.ErrNotFound, provenance.SidecarPath(path))
+	}
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(res, output.IsPretty(cmd))
+	}
+
+	p := res.Provenance
+	output.PrintKeyValue([][]string{
+		{"FILE", res.File},
+		{"MODEL", p.Model},
+		{"PROMPT", p.Prompt},
+		{"SEED", p.Seed},
+		{"REQUEST ID", p.RequestID},
+		{"CREATED", p.CreatedAt},
+		{"TOOL", p.Tool},
+		{"SIDECAR", res.Sidecar},
+	})
+	if len(res.Payload) > 0 {
+		fmt.Println("\nPayload:")
+		return output.PrintJSON(res.Payload, true)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/jobs"
+	"github.com/the20100/fal-cli/internal/output"
+)
+
+var jobsCmd = &cobra.Command{
+	Use:         "jobs",
+	Short:       "Browse requests recorded in the local job ledger",
+	Annotations: map[string]string{annotationOffline: "true"},
+	Long: `Every request submitted by run, generate, edit, their banana variants and
+queue submit is recorded in a local ledger (jobs.json in the config dir)
+with its model, payload, timestamps, final status and output URLs.
+
+Because the ledger remembers which model a request belongs to, the queue
+commands accept just a request ID, or any unique prefix of one:
+  fal queue poll 7f3a`,
+}
+
+var jobsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recorded jobs, most recent first",
+	Long: `List recorded jobs, most recent first.
+
+Examples:
+  fal jobs list
+  fal jobs list --limit 50
+  fal jobs list --model fal-ai/flux/dev --status FAILED`,
+	Args: cobra.NoArgs,
+	RunE: runJobsList,
+}
+
+var jobsShowCmd = &cobra.Command{
+	Use:   "show <request-id>",
+	Short: "Show a recorded job (accepts a request ID prefix)",
+	Long: `Show everything recorded for one job: model, mode, status, timestamps,
+payload and output URLs.
+
+Examples:
+  fal jobs show 7f3a9c2e-1b4d-4e8f-9a0b-123456789abc
+  fal jobs show 7f3a`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runJobsShow,
+	ValidArgsFunction: completeRequestID,
+}
+
+var (
+	jobsLimitFlag  int
+	jobsModelFlag  string
+	jobsStatusFlag string
+)
+
+func init() {
+	jobsListCmd.Flags().IntVar(&jobsLimitFlag, "limit", 20, "Max number of jobs to show (0 = all)")
+	jobsListCmd.Flags().StringVar(&jobsModelFlag, "model", "", "Only show jobs for this model ID")
+	jobsListCmd.Flags().StringVar(&jobsStatusFlag, "status", "", "Only show jobs with this status (e.g. COMPLETED, FAILED)")
+
+	jobsCmd.AddCommand(jobsListCmd, jobsShowCmd)
+	rootCmd.AddCommand(jobsCmd)
+}
+
+func runJobsList(cmd *cobra.Command, args []string) error {
+	all, err := jobs.List()
+	if err != nil {
+		return err
+	}
+
+	var list []jobs.Job
+	for _, j := range all {
+		if jobsModelFlag != "" && j.ModelID != jobsModelFlag {
+			continue
+		}
+		if jobsStatusFlag != "" && !strings.EqualFold(j.Status, jobsStatusFlag) {
+			continue
+		}
+		list = append(list, j)
+		if jobsLimitFlag > 0 && len(list) == jobsLimitFlag {
+			break
+		}
+	}
+
+	if output.IsJSON(cmd) {
+		if list == nil {
+			list = []jobs.Job{}
+		}
+		return output.PrintJSON(list, output.IsPretty(cmd))
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No jobs recorded.")
+		return nil
+	}
+
+	headers := []string{"REQUEST ID", "MODEL", "MODE", "STATUS", "SUBMITTED", "OUTPUTS"}
+	rows := make([][]string, len(list))
+	for i, j := range list {
+		rows[i] = []string{
+			j.RequestID,
+			j.ModelID,
+			j.Mode,
+			j.Status,
+			j.SubmittedAt.Local().Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%d", len(j.Outputs)),
+		}
+	}
+	output.PrintTable(headers, rows)
+	return nil
+}
+
+func runJobsShow(cmd *cobra.Command, args []string) error {
+	j, err := jobs.Find(args[0])
+	if err != nil {
+		return err
+	}
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(j, output.IsPretty(cmd))
+	}
+
+	output.PrintKeyValue([][]string{
+		{"REQUEST ID", j.RequestID},
+		{"MODEL", j.ModelID},
+		{"MODE", j.Mode},
+		{"STATUS", j.Status},
+		{"ERROR", j.Error},
+		{"SUBMITTED", j.SubmittedAt.Local().Format(time.RFC3339)},
+		{"UPDATED", j.UpdatedAt.Local().Format(time.RFC3339)},
+	})
+
+	if len(j.Outputs) > 0 {
+		fmt.Println("\nOutputs:")
+		for i, u := range j.Outputs {
+			fmt.Printf("  [%d] %s\n", i+1, u)
+		}
+	}
+	if len(j.Payload) > 0 {
+		fmt.Println("\nPayload:")
+		return output.PrintJSON(j.Payload, true)
+	}
+	return nil
+}
+
+// resolveQueueArgs returns the model and request IDs for a queue command
+// that takes "[model-id] <request-id>". With a single argument, the model is
+// looked up in the job ledger, which also expands a request ID prefix.
+func resolveQueueArgs(args []string) (modelID, requestID string, err error) {
+	if len(args) == 2 {
+		return args[0], args[1], nil
+	}
+	j, err := jobs.Find(args[0])
+	if err != nil {
+		if errors.Is(err, jobs.ErrNotFound) {
+			return "", "", fmt.Errorf("%w — pass the model ID too: <model-id> <request-id>", err)
+		}
+		return "", "", err
+	}
+	return j.ModelID, j.RequestID, nil
+}
+
+// recordJob adds a submission to the job ledger. Ledger errors are reported
+// as warnings and never fail the command.
+func recordJob(j jobs.Job) {
+	if err := jobs.Record(j); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not record job in ledger: %s\n", err)
+	}
+}
+
+// finishJob records how a request ended: its output URLs on success, or
+// its final status and error otherwise. Unknown request IDs are ignored.
+func finishJob(requestID string, result []byte, runErr error) {
+	err := jobs.Update(requestID, func(j *jobs.Job) {
+		var qe *api.QueueError
+		switch {
+		case runErr == nil:
+			j.Status = api.StatusCompleted
+			j.Error = ""
+			j.Outputs = resultURLs(result)
+		case errors.As(runErr, &qe):
+			j.Status = qe.Status
+			j.Error = qe.Message
+		case errors.Is(runErr, errTimedOut), errors.Is(runErr, context.Canceled):
+			j.Status = jobs.StatusCancelled
+			j.Error = runErr.Error()
+		default:
+			j.Error = runErr.Error()
+		}
+	})
+	if err != nil && !errors.Is(err, jobs.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "Warning: could not update job ledger: %s\n", err)
+	}
+}
+
+// updateJobStatus records a status seen by "queue status". Unknown request
+// IDs are ignored.
+func updateJobStatus(requestID, status string) {
+	err := jobs.Update(requestID, func(j *jobs.Job) {
+		j.Status = status
+	})
+	if err != nil && !errors.Is(err, jobs.ErrNotFound) {
+		fmt.Fprintf(os.Stderr, "Warning: could not update job ledger: %s\n", err)
+	}
+}
+
+// jobPayload returns the payload recorded for requestID, or nil.
+func jobPayload(requestID string) map[string]any {
+	j, err := jobs.Find(requestID)
+	if err != nil || j.RequestID != requestID {
+		return nil
+	}
+	return j.Payload
+}
+
+// resultURLs returns the remote URLs of every file in a model result.
+// Inline data URLs are skipped.
+func resultURLs(result []byte) []string {
+	var urls []string
+	for _, f := range api.FindFiles(result) {
+		if !strings.HasPrefix(f.URL, "data:") {
+			urls = append(urls, f.URL)
+		}
+	}
+	return urls
+}
+
+// localRequestID returns an ID for a sync request whose response did not
+// carry one, so it can still be recorded in the ledger.
+func localRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "local-" + hex.EncodeToString(b)
+}
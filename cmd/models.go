@@ -1,11 +1,16 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/catalog"
 	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/schema"
 )
 
 var modelsCmd = &cobra.Command{
@@ -18,11 +23,19 @@ var modelsListCmd = &cobra.Command{
 	Short: "List models from the fal.ai catalog",
 	Long: `List models from the fal.ai catalog with optional filters.
 
+The catalog is paginated: pages are fetched until --limit models have been
+listed, and the cursor of the next page is shown so a later call can resume
+with --cursor. --all follows every page instead; with JSON output the models
+are then streamed as NDJSON, one object per line.
+
 Examples:
   fal models list
   fal models list --category text-to-image
   fal models list --search "flux"
-  fal models list --category image-to-video --limit 10`,
+  fal models list --category image-to-video --limit 10
+  fal models list --cursor <next-cursor>
+  fal models list --all --json > catalog.ndjson
+  fal models list --offline --search "flux dev"`,
 	RunE: runModelsList,
 }
 
@@ -31,47 +44,151 @@ var modelsPricingCmd = &cobra.Command{
 	Short: "Show pricing for one or more models",
 	Long: `Show pricing for one or more fal.ai model endpoints.
 
+Every page of results is fetched. With --all and no model IDs, the prices of
+the whole catalog are listed; with JSON output they are streamed as NDJSON,
+one object per line.
+
 Examples:
   fal models pricing fal-ai/nano-banana-pro
   fal models pricing fal-ai/flux/dev fal-ai/flux/schnell
-  fal models pricing fal-ai/nano-banana-pro fal-ai/nano-banana-pro/edit`,
-	Args: cobra.MinimumNArgs(1),
-	RunE: runModelsPricing,
+  fal models pricing fal-ai/nano-banana-pro fal-ai/nano-banana-pro/edit
+  fal models pricing --all --json > prices.ndjson`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 && !pricingAllFlag {
+			return fmt.Errorf("requires at least one model ID, or --all for every model")
+		}
+		return nil
+	},
+	RunE:              runModelsPricing,
+	ValidArgsFunction: completeModelIDs,
+}
+
+var modelsInfoCmd = &cobra.Command{
+	Use:   "info <model-id>",
+	Short: "Show a model's details, pricing and input/output fields",
+	Long: `Show everything needed to wire up a model in one place: catalog metadata
+(description, tags, status, last update, page URL), pricing, and a summary
+of the fields it takes and returns, from its OpenAPI schema.
+
+Examples:
+  fal models info fal-ai/flux/dev
+  fal models info fal-ai/nano-banana-pro/edit --json`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runModelsInfo,
+	ValidArgsFunction: completeModelID,
+}
+
+var modelsSchemaCmd = &cobra.Command{
+	Use:   "schema <model-id>",
+	Short: "Print a model's input and/or output JSON schema",
+	Long: `Print a model's input and/or output schema, from its OpenAPI document
+(cached for a day in the config dir).
+
+In a terminal the fields are rendered as a table, nested fields indented;
+with --json (or when piped) the JSON schema is printed with references
+inlined. --raw prints the whole OpenAPI document as published.
+
+Examples:
+  fal models schema fal-ai/flux/dev
+  fal models schema fal-ai/flux/dev --input --json
+  fal models schema fal-ai/flux/dev --output
+  fal models schema fal-ai/flux/dev --raw --refresh`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runModelsSchema,
+	ValidArgsFunction: completeModelID,
 }
 
 var (
 	modelsSearchFlag   string
 	modelsCategoryFlag string
 	modelsLimitFlag    int
+	modelsPageSizeFlag int
+	modelsCursorFlag   string
+	modelsAllFlag      bool
+	modelsOfflineFlag  bool
+
+	pricingPageSizeFlag int
+	pricingCursorFlag   string
+	pricingAllFlag      bool
+
+	schemaInputFlag   bool
+	schemaOutputFlag  bool
+	schemaRawFlag     bool
+	schemaRefreshFlag bool
 )
 
 func init() {
 	modelsListCmd.Flags().StringVar(&modelsSearchFlag, "search", "", "Free-text search query")
 	modelsListCmd.Flags().StringVar(&modelsCategoryFlag, "category", "", "Filter by category (e.g. text-to-image, image-to-video)")
 	modelsListCmd.Flags().IntVar(&modelsLimitFlag, "limit", 20, "Max number of models to return")
+	modelsListCmd.Flags().IntVar(&modelsPageSizeFlag, "page-size", 0, "Models per API request (default: --limit, or the API default with --all)")
+	modelsListCmd.Flags().StringVar(&modelsCursorFlag, "cursor", "", "Start from this page cursor (from a previous listing)")
+	modelsListCmd.Flags().BoolVar(&modelsAllFlag, "all", false, "Follow every page, ignoring --limit (NDJSON with JSON output)")
+	modelsListCmd.Flags().BoolVar(&modelsOfflineFlag, "offline", false, `List from the local catalog ("fal models sync"), with fuzzy --search`)
+
+	modelsPricingCmd.Flags().IntVar(&pricingPageSizeFlag, "page-size", 0, "Prices per API request (default: API default)")
+	modelsPricingCmd.Flags().StringVar(&pricingCursorFlag, "cursor", "", "Start from this page cursor")
+	modelsPricingCmd.Flags().BoolVar(&pricingAllFlag, "all", false, "List every model's price when no IDs are given (NDJSON with JSON output)")
 
-	modelsCmd.AddCommand(modelsListCmd, modelsPricingCmd)
+	modelsSchemaCmd.Flags().BoolVar(&schemaInputFlag, "input", false, "Only the input schema")
+	modelsSchemaCmd.Flags().BoolVar(&schemaOutputFlag, "output", false, "Only the output schema")
+	modelsSchemaCmd.Flags().BoolVar(&schemaRawFlag, "raw", false, "Print the whole OpenAPI document as published")
+	modelsSchemaCmd.Flags().BoolVar(&schemaRefreshFlag, "refresh", false, "Fetch the schema again instead of using the cached copy")
+	modelsSchemaCmd.MarkFlagsMutuallyExclusive("input", "output")
+
+	modelsCmd.AddCommand(modelsListCmd, modelsPricingCmd, modelsInfoCmd, modelsSchemaCmd)
 	rootCmd.AddCommand(modelsCmd)
 }
 
 func runModelsList(cmd *cobra.Command, args []string) error {
-	resp, err := client.ListModels(modelsSearchFlag, modelsCategoryFlag, "", modelsLimitFlag)
-	if err != nil {
+	if modelsOfflineFlag {
+		return runModelsListOffline(cmd)
+	}
+
+	pageSize := modelsPageSizeFlag
+	if pageSize == 0 && !modelsAllFlag {
+		pageSize = modelsLimitFlag
+	}
+	pages := client.ModelPages(cmd.Context(), modelsSearchFlag, modelsCategoryFlag, modelsCursorFlag, pageSize)
+
+	if modelsAllFlag && output.IsJSON(cmd) {
+		return streamPages(pages)
+	}
+
+	var models []api.Model
+	for (modelsAllFlag || len(models) < modelsLimitFlag) && pages.Next() {
+		models = append(models, pages.Page()...)
+	}
+	if err := pages.Err(); err != nil {
 		return err
 	}
+	// A page that overshoots --limit is cut short; its cursor would then skip
+	// the dropped models, so it is not offered for resuming.
+	next := pages.Cursor()
+	truncated := !modelsAllFlag && len(models) > modelsLimitFlag
+	if truncated {
+		models = models[:modelsLimitFlag]
+		next = ""
+	}
 
 	if output.IsJSON(cmd) {
-		return output.PrintJSON(resp.Models, output.IsPretty(cmd))
+		if models == nil {
+			models = []api.Model{}
+		}
+		if next != "" {
+			fmt.Fprintf(os.Stderr, "More available; next cursor: %s\n", next)
+		}
+		return output.PrintJSON(models, output.IsPretty(cmd))
 	}
 
-	if len(resp.Models) == 0 {
+	if len(models) == 0 {
 		fmt.Println("No models found.")
 		return nil
 	}
 
 	headers := []string{"ENDPOINT ID", "NAME", "CATEGORY", "STATUS"}
-	rows := make([][]string, len(resp.Models))
-	for i, m := range resp.Models {
+	rows := make([][]string, len(models))
+	for i, m := range models {
 		rows[i] = []string{
 			m.EndpointID,
 			output.Truncate(m.Metadata.DisplayName, 35),
@@ -81,30 +198,42 @@ func runModelsList(cmd *cobra.Command, args []string) error {
 	}
 	output.PrintTable(headers, rows)
 
-	if resp.HasMore {
-		fmt.Printf("\n(%d shown, more available — increase --limit to see more)\n", len(resp.Models))
+	switch {
+	case next != "":
+		fmt.Printf("\n(%d shown, more available — next page: --cursor %s, or --all for everything)\n", len(models), next)
+	case truncated:
+		fmt.Printf("\n(%d shown, more available — raise --limit or use --all to see more)\n", len(models))
 	}
 	return nil
 }
 
 func runModelsPricing(cmd *cobra.Command, args []string) error {
-	resp, err := client.GetModelPricing(args)
+	pages := client.PricingPages(cmd.Context(), args, pricingCursorFlag, pricingPageSizeFlag)
+
+	if pricingAllFlag && output.IsJSON(cmd) {
+		return streamPages(pages)
+	}
+
+	prices, err := pages.All()
 	if err != nil {
 		return err
 	}
 
 	if output.IsJSON(cmd) {
-		return output.PrintJSON(resp.Prices, output.IsPretty(cmd))
+		if prices == nil {
+			prices = []api.ModelPrice{}
+		}
+		return output.PrintJSON(prices, output.IsPretty(cmd))
 	}
 
-	if len(resp.Prices) == 0 {
+	if len(prices) == 0 {
 		fmt.Println("No pricing info found for the given model(s).")
 		return nil
 	}
 
 	headers := []string{"ENDPOINT ID", "PRICE", "UNIT", "CURRENCY"}
-	rows := make([][]string, len(resp.Prices))
-	for i, p := range resp.Prices {
+	rows := make([][]string, len(prices))
+	for i, p := range prices {
 		rows[i] = []string{
 			p.EndpointID,
 			fmt.Sprintf("%.4f", p.UnitPrice),
@@ -115,3 +244,281 @@ func runModelsPricing(cmd *cobra.Command, args []string) error {
 	output.PrintTable(headers, rows)
 	return nil
 }
+
+// runModelsListOffline lists models from the local catalog.
+func runModelsListOffline(cmd *cobra.Command) error {
+	c, err := loadCatalog()
+	if err != nil {
+		return err
+	}
+	models := filterCategory(catalog.Search(c.Models, modelsSearchFlag), modelsCategoryFlag)
+
+	if modelsAllFlag && output.IsJSON(cmd) {
+		for _, m := range models {
+			if err := output.PrintJSON(m, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	total := len(models)
+	if !modelsAllFlag && total > modelsLimitFlag {
+		models = models[:modelsLimitFlag]
+	}
+	if err := printCatalogModels(cmd, c, models); err != nil {
+		return err
+	}
+	if !output.IsJSON(cmd) && len(models) < total {
+		fmt.Printf("\n(%d of %d shown — raise --limit or use --all to see more)\n", len(models), total)
+	}
+	return nil
+}
+
+// streamPages writes every item of the remaining pages as NDJSON, one
+// compact object per line, as soon as each page arrives.
+func streamPages[T any](pages *api.Pager[T]) error {
+	for pages.Next() {
+		for _, item := range pages.Page() {
+			if err := output.PrintJSON(item, false); err != nil {
+				return err
+			}
+		}
+	}
+	return pages.Err()
+}
+
+// fieldInfo summarizes one field of a model's input or output.
+type fieldInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Enum        []any  `json:"enum,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// modelInfo is the JSON output of "models info".
+type modelInfo struct {
+	EndpointID string            `json:"endpoint_id"`
+	Metadata   api.ModelMetadata `json:"metadata"`
+	Pricing    *api.ModelPrice   `json:"pricing"`
+	Input      []fieldInfo       `json:"input"`
+	Output     []fieldInfo       `json:"output"`
+}
+
+func runModelsInfo(cmd *cobra.Command, args []string) error {
+	modelID := args[0]
+
+	m, err := client.GetModel(cmd.Context(), modelID, true)
+	if err != nil {
+		return err
+	}
+	info := modelInfo{EndpointID: m.EndpointID, Metadata: m.Metadata, Input: []fieldInfo{}, Output: []fieldInfo{}}
+
+	if len(m.OpenAPI) > 0 {
+		if err := schema.Store(modelID, m.OpenAPI); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not cache schema: %s\n", err)
+		}
+		if ep, err := schema.Parse(modelID, m.OpenAPI); err == nil {
+			info.Input = fieldInfos(ep, ep.Input)
+			info.Output = fieldInfos(ep, ep.Output)
+		}
+	}
+
+	// Pricing is best effort: the details are useful without it.
+	if pr, err := client.GetModelPricing(cmd.Context(), []string{modelID}); err == nil {
+		for i := range pr.Prices {
+			if pr.Prices[i].EndpointID == modelID {
+				info.Pricing = &pr.Prices[i]
+			}
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "Warning: could not fetch pricing: %s\n", err)
+	}
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(info, output.IsPretty(cmd))
+	}
+
+	md := info.Metadata
+	price := "-"
+	if info.Pricing != nil {
+		price = output.FormatPrice(info.Pricing.UnitPrice, info.Pricing.Unit, strings.ToUpper(info.Pricing.Currency))
+	}
+	output.PrintKeyValue([][]string{
+		{"ENDPOINT ID", info.EndpointID},
+		{"NAME", md.DisplayName},
+		{"CATEGORY", md.Category},
+		{"STATUS", md.Status},
+		{"TAGS", strings.Join(md.Tags, ", ")},
+		{"UPDATED", md.UpdatedAt},
+		{"PRICE", price},
+		{"URL", md.ModelURL},
+	})
+	if md.Description != "" {
+		fmt.Printf("\n%s\n", strings.TrimSpace(md.Description))
+	}
+
+	for _, part := range []struct {
+		title  string
+		fields []fieldInfo
+	}{{"Input", info.Input}, {"Output", info.Output}} {
+		if len(part.fields) == 0 {
+			continue
+		}
+		fmt.Printf("\n%s:\n", part.title)
+		rows := make([][]string, len(part.fields))
+		for i, f := range part.fields {
+			rows[i] = []string{f.Name, f.Type, yesOrEmpty(f.Required), formatDefault(f.Default), output.Truncate(f.Description, 60)}
+		}
+		output.PrintTable([]string{"FIELD", "TYPE", "REQUIRED", "DEFAULT", "DESCRIPTION"}, rows)
+	}
+	return nil
+}
+
+func runModelsSchema(cmd *cobra.Command, args []string) error {
+	modelID := args[0]
+
+	raw, err := loadSchemaDoc(cmd.Context(), modelID, schemaRefreshFlag)
+	if err != nil {
+		return err
+	}
+
+	if schemaRawFlag {
+		var doc any
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return err
+		}
+		return output.PrintJSON(doc, output.IsPretty(cmd) || !output.IsJSON(cmd))
+	}
+
+	ep, err := schema.Parse(modelID, raw)
+	if err != nil {
+		return err
+	}
+	showInput, showOutput := !schemaOutputFlag, !schemaInputFlag
+
+	if output.IsJSON(cmd) {
+		switch {
+		case !showOutput:
+			return output.PrintJSON(ep.Inline(ep.Input), output.IsPretty(cmd))
+		case !showInput:
+			return output.PrintJSON(ep.Inline(ep.Output), output.IsPretty(cmd))
+		}
+		return output.PrintJSON(map[string]*schema.Schema{
+			"input":  ep.Inline(ep.Input),
+			"output": ep.Inline(ep.Output),
+		}, output.IsPretty(cmd))
+	}
+
+	headers := []string{"FIELD", "TYPE", "REQUIRED", "DEFAULT", "DESCRIPTION"}
+	if showInput {
+		fmt.Printf("Input (%s):\n", modelID)
+		var rows [][]string
+		schemaRows(ep, ep.Input, "", map[*schema.Schema]bool{}, &rows)
+		output.PrintTable(headers, rows)
+	}
+	if showOutput {
+		if showInput {
+			fmt.Println()
+		}
+		fmt.Printf("Output (%s):\n", modelID)
+		if ep.Output == nil {
+			fmt.Println("  (not described by the schema)")
+			return nil
+		}
+		var rows [][]string
+		schemaRows(ep, ep.Output, "", map[*schema.Schema]bool{}, &rows)
+		output.PrintTable(headers, rows)
+	}
+	return nil
+}
+
+// fieldInfos summarizes the top-level fields of an object schema.
+func fieldInfos(ep *schema.Endpoint, s *schema.Schema) []fieldInfo {
+	fields := ep.Fields(s)
+	out := make([]fieldInfo, 0, len(fields))
+	for _, f := range fields {
+		fi := fieldInfo{Name: f.Name, Type: ep.TypeName(f.Schema), Required: f.Required}
+		if f.Schema != nil {
+			fi.Default = f.Schema.Default
+			fi.Enum = f.Schema.Enum
+			fi.Description = strings.TrimSpace(f.Schema.Description)
+		}
+		out = append(out, fi)
+	}
+	return out
+}
+
+// schemaRows renders the fields of an object schema as table rows. Fields
+// of nested objects (including arrays of objects and object alternatives)
+// follow their parent, indented. seen guards against recursive schemas.
+func schemaRows(ep *schema.Endpoint, s *schema.Schema, indent string, seen map[*schema.Schema]bool, rows *[][]string) {
+	s = ep.Resolve(s)
+	if s == nil || seen[s] {
+		return
+	}
+	seen[s] = true
+	defer delete(seen, s)
+
+	for _, f := range ep.Fields(s) {
+		var def, desc string
+		if f.Schema != nil {
+			def = formatDefault(f.Schema.Default)
+			desc, _, _ = strings.Cut(strings.TrimSpace(f.Schema.Description), "\n")
+			if enum := paramEnum(ep, f.Schema); len(enum) > 0 {
+				desc = strings.TrimSpace(desc + " [" + strings.Join(enum, ", ") + "]")
+			}
+		}
+		*rows = append(*rows, []string{indent + f.Name, ep.TypeName(f.Schema), yesOrEmpty(f.Required), def, output.Truncate(desc, 70)})
+		if nested := nestedObject(ep, f.Schema); nested != nil {
+			schemaRows(ep, nested, indent+"  ", seen, rows)
+		}
+	}
+}
+
+// nestedObject returns the object schema whose fields belong under a
+// field: the field itself, its array items, or its object alternative.
+func nestedObject(ep *schema.Endpoint, s *schema.Schema) *schema.Schema {
+	s = ep.Resolve(s)
+	if s == nil {
+		return nil
+	}
+	if len(s.Properties) > 0 {
+		return s
+	}
+	if s.Items != nil {
+		return nestedObject(ep, s.Items)
+	}
+	var found *schema.Schema
+	for _, alt := range append(append([]*schema.Schema{}, s.AnyOf...), s.OneOf...) {
+		if r := ep.Resolve(alt); r != nil && len(r.Properties) > 0 {
+			if found != nil {
+				return nil // ambiguous
+			}
+			found = r
+		}
+	}
+	return found
+}
+
+func formatDefault(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func yesOrEmpty(b bool) string {
+	if b {
+		return "yes"
+	}
+	return ""
+}
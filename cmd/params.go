@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/the20100/fal-cli/internal/schema"
+)
+
+// Model parameter flags: "fal run <model-id> --num-images 2" sets the
+// payload field num_images. The flags are generated at runtime from the
+// model's input schema, which is why run parses its own flags.
+
+// paramValue is the pflag.Value of a flag generated for one property of a
+// model's input schema. It converts the flag's text to the property type.
+type paramValue struct {
+	field    string // payload field name
+	kind     string // string, integer, number, boolean, array or json
+	itemKind string // kind of the items, for arrays
+	typeName string
+	def      string // default shown in help
+	value    any
+	set      bool
+}
+
+func (p *paramValue) String() string {
+	if !p.set {
+		return p.def
+	}
+	if s, ok := p.value.(string); ok {
+		return s
+	}
+	b, _ := json.Marshal(p.value)
+	return string(b)
+}
+
+func (p *paramValue) Type() string {
+	if p.kind == "boolean" {
+		return "bool" // lets pflag print it as a plain switch
+	}
+	return p.typeName
+}
+
+func (p *paramValue) Set(s string) error {
+	if p.kind == "array" {
+		// Repeatable, or a whole JSON array at once.
+		if strings.HasPrefix(strings.TrimSpace(s), "[") {
+			var items []any
+			if err := json.Unmarshal([]byte(s), &items); err != nil {
+				return fmt.Errorf("invalid JSON array: %w", err)
+			}
+			p.value, p.set = items, true
+			return nil
+		}
+		item, err := parseParam(s, p.itemKind)
+		if err != nil {
+			return err
+		}
+		items, _ := p.value.([]any)
+		p.value, p.set = append(items, item), true
+		return nil
+	}
+
+	v, err := parseParam(s, p.kind)
+	if err != nil {
+		return err
+	}
+	p.value, p.set = v, true
+	return nil
+}
+
+// parseParam converts flag text to a value of the given kind.
+func parseParam(s, kind string) (any, error) {
+	switch kind {
+	case "string":
+		return s, nil
+	case "integer":
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		return n, nil
+	case "number":
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number")
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false")
+		}
+		return b, nil
+	}
+	return parseSetValue(s), nil
+}
+
+// paramKind maps a resolved property schema to the kind of flag value.
+// Anything that is not a plain scalar or array is parsed like --set values.
+func paramKind(s *schema.Schema) string {
+	if s == nil || len(s.AnyOf) > 0 || len(s.OneOf) > 0 || len(s.Type) != 1 {
+		return "json"
+	}
+	switch t := s.Type[0]; t {
+	case "string", "integer", "number", "boolean", "array":
+		return t
+	}
+	return "json"
+}
+
+// addParamFlags registers a flag on cmd for every property of the model's
+// input schema, named after the property with "_" replaced by "-". Names
+// already taken by the command's own flags are skipped; those fields can
+// still be set with --set. It returns the flags it added.
+func addParamFlags(cmd *cobra.Command, ep *schema.Endpoint) *pflag.FlagSet {
+	params := pflag.NewFlagSet("model parameters", pflag.ContinueOnError)
+	params.SortFlags = false // keep the schema's order, required first
+	params.SetNormalizeFunc(underscoreToDash)
+	for _, f := range ep.Fields(ep.Input) {
+		name := strings.ReplaceAll(f.Name, "_", "-")
+		if cmd.Flags().Lookup(name) != nil {
+			continue
+		}
+		v := &paramValue{field: f.Name, kind: paramKind(f.Schema), typeName: ep.TypeName(f.Schema)}
+		if v.kind == "array" {
+			v.itemKind = paramKind(ep.Resolve(f.Schema.Items))
+		}
+		if f.Schema != nil && f.Schema.Default != nil {
+			if s, ok := f.Schema.Default.(string); ok {
+				v.def = s
+			} else if b, err := json.Marshal(f.Schema.Default); err == nil {
+				v.def = string(b)
+			}
+		}
+		flag := params.VarPF(v, name, "", paramUsage(ep, f))
+		if v.kind == "boolean" {
+			flag.NoOptDefVal = "true"
+		}
+	}
+	cmd.Flags().SetNormalizeFunc(underscoreToDash)
+	cmd.Flags().AddFlagSet(params)
+	return params
+}
+
+// paramUsage builds the help text of a parameter flag from its schema:
+// the first line of the description, then allowed values or range.
+func paramUsage(ep *schema.Endpoint, f schema.Field) string {
+	var parts []string
+	s := f.Schema
+	if s != nil {
+		desc, _, _ := strings.Cut(strings.TrimSpace(s.Description), "\n")
+		desc = strings.TrimSuffix(strings.TrimSpace(desc), ".")
+		if desc != "" {
+			parts = append(parts, desc)
+		}
+		if enum := paramEnum(ep, s); len(enum) > 0 {
+			parts = append(parts, "one of: "+strings.Join(enum, ", "))
+		}
+		switch {
+		case s.Minimum != nil && s.Maximum != nil:
+			parts = append(parts, fmt.Sprintf("range %s to %s", formatNumber(*s.Minimum), formatNumber(*s.Maximum)))
+		case s.Minimum != nil:
+			parts = append(parts, "min "+formatNumber(*s.Minimum))
+		case s.Maximum != nil:
+			parts = append(parts, "max "+formatNumber(*s.Maximum))
+		}
+		if paramKind(s) == "array" {
+			parts = append(parts, "repeatable")
+		}
+	}
+	usage := strings.Join(parts, "; ")
+	if f.Required {
+		usage = strings.TrimSpace("(required) " + usage)
+	}
+	return usage
+}
+
+// paramEnum lists the allowed values of s, looking into anyOf/oneOf
+// alternatives (e.g. a preset name or a custom object).
+func paramEnum(ep *schema.Endpoint, s *schema.Schema) []string {
+	var out []string
+	add := func(s *schema.Schema) {
+		for _, v := range s.Enum {
+			out = append(out, fmt.Sprint(v))
+		}
+	}
+	add(s)
+	for _, alt := range append(append([]*schema.Schema{}, s.AnyOf...), s.OneOf...) {
+		if r := ep.Resolve(alt); r != nil {
+			add(r)
+		}
+	}
+	return out
+}
+
+// paramValues returns the fields set through parameter flags.
+func paramValues(params *pflag.FlagSet) map[string]any {
+	values := map[string]any{}
+	if params == nil {
+		return values
+	}
+	params.VisitAll(func(f *pflag.Flag) {
+		if v, ok := f.Value.(*paramValue); ok && v.set {
+			values[v.field] = v.value
+		}
+	})
+	return values
+}
+
+// suggestFlag returns the flag of cmd closest to a mistyped name, or "".
+func suggestFlag(cmd *cobra.Command, name string) string {
+	var names []string
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if !f.Hidden && f.Deprecated == "" {
+			names = append(names, f.Name)
+		}
+	})
+	return schema.Suggest(name, names)
+}
+
+func underscoreToDash(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	return pflag.NormalizedName(strings.ReplaceAll(name, "_", "-"))
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// discardValue stands in for run's own flags during the first argument
+// scan, so their values are skipped without being applied twice.
+type discardValue struct{ typ string }
+
+func (d discardValue) String() string   { return "" }
+func (d discardValue) Set(string) error { return nil }
+func (d discardValue) Type() string     { return d.typ }
+
+// scanRunArgs finds the model ID in run's raw arguments, and whether help
+// was requested, before the model's parameter flags are known. Global flags
+// are applied so setup can use them; unknown flags (model parameters) are
+// skipped along with their value.
+//
+// Whether a model parameter takes a value is only known from the schema, so
+// "--enable-safety-checker fal-ai/flux/dev" would take the model ID as the
+// flag's value: parameters must come after the model ID.
+func scanRunArgs(cmd *cobra.Command, args []string) (modelID string, help bool, err error) {
+	inherited := cmd.InheritedFlags() // also merges them into cmd.Flags()
+
+	scan := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	scan.SetOutput(io.Discard)
+	scan.ParseErrorsWhitelist.UnknownFlags = true
+	scan.SetNormalizeFunc(underscoreToDash)
+	scan.AddFlagSet(inherited)
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Name == "help" {
+			return
+		}
+		scan.AddFlag(&pflag.Flag{
+			Name:        f.Name,
+			Shorthand:   f.Shorthand,
+			NoOptDefVal: f.NoOptDefVal,
+			Value:       discardValue{f.Value.Type()},
+		})
+	})
+	scan.BoolVarP(&help, "help", "h", false, "")
+
+	if err := scan.Parse(args); err != nil {
+		return "", false, err
+	}
+	modelID = scan.Arg(0)
+	if help {
+		return modelID, help, nil
+	}
+	for _, a := range args {
+		if a == "--" || (modelID != "" && a == modelID) {
+			break
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(a, "--"), "=")
+		if strings.HasPrefix(a, "--") && scan.Lookup(name) == nil {
+			return "", false, fmt.Errorf("--%s comes before the model ID: model parameters go after it, as in fal run <model-id> --%s ...", name, name)
+		}
+	}
+	return modelID, help, nil
+}
+
+// printRunHelp prints the help of "fal run <model-id>", listing the model's
+// parameter flags apart from run's own flags.
+func printRunHelp(cmd *cobra.Command, modelID string, static, params *pflag.FlagSet, schemaErr error) error {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Run %s.\n\nUsage:\n  fal run %s [--<parameter> value ...] [flags]\n\n", modelID, modelID)
+	switch {
+	case params != nil && params.HasFlags():
+		fmt.Fprintf(out, "Model parameters:\n%s\n", params.FlagUsages())
+	case schemaErr != nil:
+		fmt.Fprintf(out, "Model parameters: unavailable (%s)\n\n", schemaErr)
+	default:
+		fmt.Fprintf(out, "Model parameters: none\n\n")
+	}
+	fmt.Fprintf(out, "Flags:\n%s\nGlobal Flags:\n%s", static.FlagUsages(), cmd.InheritedFlags().FlagUsages())
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanRunArgs(t *testing.T) {
+	tests := []struct {
+		args  []string
+		model string
+		help  bool
+		err   string
+	}{
+		{args: []string{"fal-ai/flux/dev"}, model: "fal-ai/flux/dev"},
+		{args: []string{"fal-ai/flux/dev", "--enable-safety-checker", "--seed", "3"}, model: "fal-ai/flux/dev"},
+		{args: []string{"--queue", "fal-ai/flux/dev", "--prompt", "a cat"}, model: "fal-ai/flux/dev"},
+		{args: []string{"--input", "@in.json", "fal-ai/flux/dev"}, model: "fal-ai/flux/dev"},
+		{args: []string{"fal-ai/flux/dev", "--help"}, model: "fal-ai/flux/dev", help: true},
+		{args: []string{"--help"}, help: true},
+		{args: nil},
+		{args: []string{"--enable-safety-checker", "fal-ai/flux/dev"}, err: "--enable-safety-checker comes before the model ID"},
+		{args: []string{"--seed", "3", "fal-ai/flux/dev"}, err: "--seed comes before the model ID"},
+		{args: []string{"--num_images=2", "fal-ai/flux/dev"}, err: "--num_images comes before the model ID"},
+	}
+	for _, tt := range tests {
+		model, help, err := scanRunArgs(runCmd, tt.args)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("scanRunArgs(%q) error = %v, want %q", tt.args, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("scanRunArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if model != tt.model || help != tt.help {
+			t.Errorf("scanRunArgs(%q) = %q, %v, want %q, %v", tt.args, model, help, tt.model, tt.help)
+		}
+	}
+}
@@ -3,83 +3,201 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/jobs"
 	"github.com/the20100/fal-cli/internal/output"
 )
 
 var queueCmd = &cobra.Command{
 	Use:   "queue",
 	Short: "Manage queue requests",
+	Long: `Manage queue requests.
+
+status, result, cancel and poll take "[model-id] <request-id>". The model ID
+can be left out for requests recorded in the local job ledger (see "fal jobs"),
+and the request ID can then be shortened to any unique prefix.`,
+}
+
+var queueSubmitCmd = &cobra.Command{
+	Use:   "submit <model-id>",
+	Short: "Submit a request to the queue and exit without waiting",
+	Long: `Submit a request to the queue and print its request ID and URLs.
+
+The command returns as soon as the request is accepted, so CI jobs can fire
+off work and collect it later with "fal queue poll" or "fal queue result".
+
+Examples:
+  fal queue submit fal-ai/flux/dev --input '{"prompt":"a cat"}'
+  fal queue submit fal-ai/flux/dev --input '{"prompt":"a cat"}' --json | jq -r .request_id
+  fal queue submit fal-ai/flux/dev --input @base.json --set seed=42`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runQueueSubmit,
+	ValidArgsFunction: completeModelID,
 }
 
 var queueStatusCmd = &cobra.Command{
-	Use:   "status <model-id> <request-id>",
+	Use:   "status [model-id] <request-id>",
 	Short: "Check the status of a queued request",
 	Long: `Check the status of a queued request.
 
-Status values: IN_QUEUE, IN_PROGRESS, COMPLETED
+Status values: IN_QUEUE, IN_PROGRESS, COMPLETED (a COMPLETED request may
+still carry an error; any other value means the request has stopped)
 
 Examples:
   fal queue status fal-ai/flux/dev abc123
-  fal queue status fal-ai/flux/dev abc123 --logs`,
-	Args: cobra.ExactArgs(2),
-	RunE: runQueueStatus,
+  fal queue status fal-ai/flux/dev abc123 --logs
+  fal queue status abc1`,
+	Args:              cobra.RangeArgs(1, 2),
+	RunE:              runQueueStatus,
+	ValidArgsFunction: completeQueueArgs,
 }
 
 var queueResultCmd = &cobra.Command{
-	Use:   "result <model-id> <request-id>",
+	Use:   "result [model-id] <request-id>",
 	Short: "Get the result of a completed queued request",
 	Long: `Retrieve the result of a completed queued request.
 
 Examples:
-  fal queue result fal-ai/flux/dev abc123`,
-	Args: cobra.ExactArgs(2),
-	RunE: runQueueResult,
+  fal queue result fal-ai/flux/dev abc123
+  fal queue result abc1
+  fal queue result abc1 --download ./out`,
+	Args:              cobra.RangeArgs(1, 2),
+	RunE:              runQueueResult,
+	ValidArgsFunction: completeQueueArgs,
 }
 
 var queueCancelCmd = &cobra.Command{
-	Use:   "cancel <model-id> <request-id>",
+	Use:   "cancel [model-id] <request-id>",
 	Short: "Cancel a queued request",
 	Long: `Cancel a request that is waiting in the queue.
 
 Only works for requests with status IN_QUEUE.
 
 Examples:
-  fal queue cancel fal-ai/flux/dev abc123`,
-	Args: cobra.ExactArgs(2),
-	RunE: runQueueCancel,
+  fal queue cancel fal-ai/flux/dev abc123
+  fal queue cancel abc1`,
+	Args:              cobra.RangeArgs(1, 2),
+	RunE:              runQueueCancel,
+	ValidArgsFunction: completeQueueArgs,
 }
 
 var queuePollCmd = &cobra.Command{
-	Use:   "poll <model-id> <request-id>",
+	Use:   "poll [model-id] <request-id>",
 	Short: "Poll a queued request until completion and print the result",
 	Long: `Poll a queued request until it completes, then print the result.
 
+Pressing Ctrl-C while polling, or reaching --timeout, cancels the request on
+the queue before exiting. Exit codes: 2 = failed, 3 = cancelled,
+4 = timed out, 130 = interrupted.
+
 Examples:
   fal queue poll fal-ai/flux/dev abc123
-  fal queue poll fal-ai/flux/dev abc123 --logs`,
-	Args: cobra.ExactArgs(2),
-	RunE: runQueuePoll,
+  fal queue poll fal-ai/flux/dev abc123 --logs
+  fal queue poll fal-ai/flux/dev abc123 --timeout 10m
+  fal queue poll abc1`,
+	Args:              cobra.RangeArgs(1, 2),
+	RunE:              runQueuePoll,
+	ValidArgsFunction: completeQueueArgs,
 }
 
+var queueInputFlag string
+var queueSetFlags []string
+var queueUpload uploadOptions
+var queueNoValidate bool
+var queueAllowOverBudget bool
 var queueLogsFlag bool
+var queueTimeoutFlag time.Duration
+var queueResultDownload downloadOptions
+var queuePollDownload downloadOptions
 
 func init() {
+	queueSubmitCmd.Flags().StringVar(&queueInputFlag, "input", "", "Input payload: inline JSON/YAML, @file, or - for stdin")
+	queueSubmitCmd.Flags().StringArrayVar(&queueSetFlags, "set", nil, "Override a payload field: key.path=value (repeatable)")
+	addUploadFlags(queueSubmitCmd, &queueUpload, uploadModeFal)
+	queueSubmitCmd.Flags().BoolVar(&queueNoValidate, "no-validate", false, "Send the payload without checking it against the model's input schema")
+	queueSubmitCmd.Flags().BoolVar(&queueAllowOverBudget, "allow-over-budget", false, "Submit even if the estimated cost exceeds a budget cap (see fal budget)")
 	queueStatusCmd.Flags().BoolVar(&queueLogsFlag, "logs", false, "Include model logs in output")
 	queuePollCmd.Flags().BoolVar(&queueLogsFlag, "logs", false, "Show model logs while polling")
+	addDownloadFlags(queueResultCmd, &queueResultDownload)
+	addDownloadFlags(queuePollCmd, &queuePollDownload)
+	queuePollCmd.Flags().DurationVar(&queueTimeoutFlag, "timeout", 0, "Cancel the request if not finished after this long, e.g. 10m (0 = no limit)")
 
-	queueCmd.AddCommand(queueStatusCmd, queueResultCmd, queueCancelCmd, queuePollCmd)
+	queueCmd.AddCommand(queueSubmitCmd, queueStatusCmd, queueResultCmd, queueCancelCmd, queuePollCmd)
 	rootCmd.AddCommand(queueCmd)
 }
 
+func runQueueSubmit(cmd *cobra.Command, args []string) error {
+	modelID := args[0]
+
+	if queueInputFlag == "" && len(queueSetFlags) == 0 {
+		return fmt.Errorf("an input payload is required: --input and/or --set")
+	}
+	payload, err := parsePayload(queueInputFlag, queueSetFlags)
+	if err != nil {
+		return err
+	}
+	if !queueNoValidate {
+		ep, err := loadSchema(cmd.Context(), modelID, false)
+		if err != nil {
+			if cmd.Context().Err() != nil {
+				return err
+			}
+			warnNoSchema(err)
+		}
+		if err := validatePayload(modelID, ep, payload); err != nil {
+			return err
+		}
+	}
+	refund, err := chargeBudget(cmd, modelID, payload, queueAllowOverBudget)
+	if err != nil {
+		return err
+	}
+	if err := uploadLocalRefs(cmd, payload, queueUpload); err != nil {
+		refund()
+		return err
+	}
+
+	sub, err := client.QueueSubmit(cmd.Context(), modelID, payload)
+	if err != nil {
+		refund()
+		return err
+	}
+	recordJob(jobs.Job{
+		RequestID: sub.RequestID,
+		ModelID:   modelID,
+		Mode:      "queue",
+		Status:    api.StatusInQueue,
+		Payload:   payload,
+	})
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(sub, output.IsPretty(cmd))
+	}
+
+	output.PrintKeyValue([][]string{
+		{"REQUEST ID", sub.RequestID},
+		{"STATUS URL", sub.StatusURL},
+		{"RESPONSE URL", sub.ResponseURL},
+		{"CANCEL URL", sub.CancelURL},
+	})
+	fmt.Fprintf(os.Stderr, "\nCollect the result with: fal queue poll %s\n", sub.RequestID)
+	return nil
+}
+
 func runQueueStatus(cmd *cobra.Command, args []string) error {
-	modelID, requestID := args[0], args[1]
+	modelID, requestID, err := resolveQueueArgs(args)
+	if err != nil {
+		return err
+	}
 
-	status, err := client.QueueStatus(modelID, requestID, queueLogsFlag)
+	status, err := client.QueueStatus(cmd.Context(), modelID, requestID, queueLogsFlag)
 	if err != nil {
 		return err
 	}
+	updateJobStatus(requestID, status.Status)
 
 	if output.IsJSON(cmd) {
 		return output.PrintJSON(status, output.IsPretty(cmd))
@@ -103,20 +221,30 @@ func runQueueStatus(cmd *cobra.Command, args []string) error {
 }
 
 func runQueueResult(cmd *cobra.Command, args []string) error {
-	modelID, requestID := args[0], args[1]
+	modelID, requestID, err := resolveQueueArgs(args)
+	if err != nil {
+		return err
+	}
 
-	body, err := client.QueueResult(modelID, requestID)
+	body, err := client.QueueResult(cmd.Context(), modelID, requestID)
 	if err != nil {
 		return err
 	}
+	finishJob(requestID, body, nil)
 
-	return printResult(cmd, body)
+	if err := printResult(cmd, body); err != nil {
+		return err
+	}
+	return saveOutputs(cmd.Context(), cmd, &queueResultDownload, modelID, requestID, jobPayload(requestID), body)
 }
 
 func runQueueCancel(cmd *cobra.Command, args []string) error {
-	modelID, requestID := args[0], args[1]
+	modelID, requestID, err := resolveQueueArgs(args)
+	if err != nil {
+		return err
+	}
 
-	if err := client.QueueCancel(modelID, requestID); err != nil {
+	if err := client.QueueCancel(cmd.Context(), modelID, requestID); err != nil {
 		return err
 	}
 
@@ -125,14 +253,21 @@ func runQueueCancel(cmd *cobra.Command, args []string) error {
 }
 
 func runQueuePoll(cmd *cobra.Command, args []string) error {
-	modelID, requestID := args[0], args[1]
+	modelID, requestID, err := resolveQueueArgs(args)
+	if err != nil {
+		return err
+	}
 
 	fmt.Fprintf(os.Stderr, "Polling: %s\n", requestID)
 
-	result, err := pollQueueUntilDone(modelID, requestID, queueLogsFlag)
+	result, err := pollQueueUntilDone(cmd.Context(), modelID, requestID, queueLogsFlag, queueTimeoutFlag)
+	finishJob(requestID, result, err)
 	if err != nil {
 		return err
 	}
 
-	return printResult(cmd, result)
+	if err := printResult(cmd, result); err != nil {
+		return err
+	}
+	return saveOutputs(cmd.Context(), cmd, &queuePollDownload, modelID, requestID, jobPayload(requestID), result)
 }
@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime"
+	"syscall"
 
 	"github.com/spf13/cobra"
 	"github.com/the20100/fal-cli/internal/api"
@@ -12,8 +16,11 @@ import (
 
 var (
 	// Persistent flags
-	jsonFlag   bool
-	prettyFlag bool
+	jsonFlag      bool
+	prettyFlag    bool
+	verboseFlag   bool
+	retriesFlag   int
+	retryPostFlag bool
 
 	// Global API client, set in PersistentPreRunE
 	client *api.Client
@@ -45,35 +52,86 @@ Examples:
 	SilenceUsage: true,
 }
 
+// Execute runs the root command. Ctrl-C (SIGINT) and SIGTERM cancel the
+// command's context so in-flight queue requests can be cancelled remotely
+// before the process exits.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		os.Exit(exitCode(err))
+	}
+}
+
+// Exit codes, so scripts can tell how a queued request ended.
+const (
+	exitError       = 1   // any other error
+	exitFailed      = 2   // the model reported a failure
+	exitCancelled   = 3   // the request was cancelled on the queue
+	exitTimedOut    = 4   // --timeout expired (the request was cancelled)
+	exitInterrupted = 130 // Ctrl-C / SIGTERM
+)
+
+// exitCode maps a command error to the process exit code.
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, errTimedOut):
+		return exitTimedOut
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, api.ErrRequestCancelled):
+		return exitCancelled
+	case errors.Is(err, api.ErrRequestFailed):
+		return exitFailed
 	}
+	return exitError
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&jsonFlag, "json", false, "Force JSON output")
 	rootCmd.PersistentFlags().BoolVar(&prettyFlag, "pretty", false, "Force pretty-printed JSON output (implies --json)")
+	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false, "Report HTTP retries on stderr")
+	rootCmd.PersistentFlags().IntVar(&retriesFlag, "retries", 3, "Max retries for 429/5xx responses (0 disables; overrides config \"retries\")")
+	rootCmd.PersistentFlags().BoolVar(&retryPostFlag, "retry-post", false, "Also retry POST submits on 429/5xx (may run a model twice)")
 	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		if isAuthCommand(cmd) || cmd.Name() == "info" {
-			return nil
+		if cmd.DisableFlagParsing {
+			return nil // parses its own flags, then calls setup
 		}
+		return setup(cmd)
+	}
 
-		key, err := resolveAPIKey()
-		if err != nil {
-			return err
-		}
+	rootCmd.AddCommand(infoCmd)
+}
 
-		client = api.NewClient(key)
+// setup loads the config and creates the API client for cmd, once its
+// flags have been parsed.
+func setup(cmd *cobra.Command) error {
+	if isAuthCommand(cmd) || cmd == infoCmd || isCompletionCommand(cmd) {
 		return nil
 	}
 
-	rootCmd.AddCommand(infoCmd)
+	var err error
+	cfg, err = config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if isOfflineCommand(cmd) {
+		return nil
+	}
+
+	key, err := resolveAPIKey()
+	if err != nil {
+		return err
+	}
+
+	client = api.NewClient(key, clientOptions(cmd)...)
+	return nil
 }
 
 var infoCmd = &cobra.Command{
 	Use:   "info",
-	Short: "Show tool info: config path, key status, and environment",
+	Short: "Show tool info: config path, key status, endpoints, and environment",
 	Run: func(cmd *cobra.Command, args []string) {
 		printInfo()
 	},
@@ -95,19 +153,34 @@ func printInfo() {
 	fmt.Printf("  config:   %s\n", config.Path())
 	fmt.Println()
 
+	fileCfg, cfgErr := config.Load()
+
 	keySource := "(not set)"
 	if t := resolveEnv(
 		"FAL_KEY", "FAL_API_KEY", "FAL_API", "API_KEY_FAL", "API_FAL", "FAL_PK", "FAL_PUBLIC",
 		"FAL_API_SECRET", "FAL_SECRET_KEY", "FAL_API_SECRET_KEY", "FAL_SECRET", "SECRET_FAL", "API_SECRET_FAL", "SK_FAL", "FAL_SK",
 	); t != "" {
 		keySource = "FAL_KEY env var (or alias)"
-	} else if c, err := config.Load(); err == nil && c.APIKey != "" {
+	} else if cfgErr == nil && fileCfg.APIKey != "" {
 		keySource = "config file"
 	}
 	fmt.Printf("  key source: %s\n", keySource)
 	fmt.Println()
+
+	if cfgErr != nil {
+		fileCfg = nil
+	}
+	_, endpoints := resolveEndpoints(fileCfg)
+	fmt.Println("  endpoints:")
+	for _, e := range endpoints {
+		fmt.Printf("    %-6s %s  (%s)\n", e.name+":", e.value, e.source)
+	}
+	fmt.Println()
 	fmt.Println("  env vars:")
 	fmt.Printf("    FAL_KEY = %s  (also accepts aliases: FAL_API_KEY, FAL_API, ...)\n", maskOrEmpty(os.Getenv("FAL_KEY")))
+	fmt.Printf("    FAL_RUN_URL   = %s\n", valueOrNotSet(os.Getenv("FAL_RUN_URL")))
+	fmt.Printf("    FAL_QUEUE_URL = %s\n", valueOrNotSet(os.Getenv("FAL_QUEUE_URL")))
+	fmt.Printf("    FAL_API_URL   = %s\n", valueOrNotSet(os.Getenv("FAL_API_URL")))
 	fmt.Println()
 	fmt.Println("  key resolution order:")
 	fmt.Println("    1. FAL_KEY env var (or aliases)")
@@ -124,6 +197,13 @@ func maskOrEmpty(v string) string {
 	return v[:4] + "..." + v[len(v)-4:]
 }
 
+func valueOrNotSet(v string) string {
+	if v == "" {
+		return "(not set)"
+	}
+	return v
+}
+
 // resolveEnv returns the value of the first non-empty environment variable from the given names.
 func resolveEnv(names ...string) string {
 	for _, name := range names {
@@ -134,7 +214,65 @@ func resolveEnv(names ...string) string {
 	return ""
 }
 
+// clientOptions builds the api.Client options from flags and config.
+// Flags take precedence over config values.
+func clientOptions(cmd *cobra.Command) []api.Option {
+	policy := api.DefaultRetryPolicy()
+	if cfg.Retries != nil {
+		policy.MaxRetries = *cfg.Retries
+	}
+	if cmd.Flags().Changed("retries") {
+		policy.MaxRetries = retriesFlag
+	}
+	policy.RetryPOST = cfg.RetryPOST || retryPostFlag
+
+	endpoints, _ := resolveEndpoints(cfg)
+	opts := []api.Option{api.WithRetryPolicy(policy), api.WithEndpoints(endpoints)}
+	if verboseFlag {
+		opts = append(opts, api.WithLogger(os.Stderr))
+	}
+	return opts
+}
+
+// endpointSetting is one base URL in effect and where its value came from.
+type endpointSetting struct {
+	name   string
+	value  string
+	source string
+}
+
+// resolveEndpoints returns the base URLs in effect. For each endpoint the
+// env var wins over the config file, which wins over the built-in default.
+// c may be nil.
+func resolveEndpoints(c *config.Config) (api.Endpoints, []endpointSetting) {
+	if c == nil {
+		c = &config.Config{}
+	}
+	pick := func(name, envVar, configured, def string) endpointSetting {
+		if v := os.Getenv(envVar); v != "" {
+			return endpointSetting{name, v, envVar + " env var"}
+		}
+		if configured != "" {
+			return endpointSetting{name, configured, "config file"}
+		}
+		return endpointSetting{name, def, "default"}
+	}
+
+	settings := []endpointSetting{
+		pick("run", "FAL_RUN_URL", c.RunURL, api.DefaultRunURL),
+		pick("queue", "FAL_QUEUE_URL", c.QueueURL, api.DefaultQueueURL),
+		pick("api", "FAL_API_URL", c.APIURL, api.DefaultAPIURL),
+	}
+	endpoints := api.Endpoints{
+		Run:   settings[0].value,
+		Queue: settings[1].value,
+		API:   settings[2].value,
+	}
+	return endpoints, settings
+}
+
 // resolveAPIKey returns the best available API key.
+// cfg must already be loaded.
 func resolveAPIKey() (string, error) {
 	// 1. Env var aliases (key and secret variants)
 	if k := resolveEnv(
@@ -145,11 +283,6 @@ func resolveAPIKey() (string, error) {
 	}
 
 	// 2. Config file
-	var err error
-	cfg, err = config.Load()
-	if err != nil {
-		return "", fmt.Errorf("failed to load config: %w", err)
-	}
 	if cfg.APIKey != "" {
 		return cfg.APIKey, nil
 	}
@@ -157,6 +290,24 @@ func resolveAPIKey() (string, error) {
 	return "", fmt.Errorf("not authenticated — run: fal auth set-key\nor set FAL_KEY env var")
 }
 
+// annotationOffline marks commands (and their subcommands) that only work on
+// local state and therefore run without an API key.
+const annotationOffline = "offline"
+
+// isOfflineCommand returns true if cmd or one of its parents is annotated
+// as offline, or if it was run with --offline.
+func isOfflineCommand(cmd *cobra.Command) bool {
+	if offline, err := cmd.Flags().GetBool("offline"); err == nil && offline {
+		return true
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[annotationOffline] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
 // isAuthCommand returns true if cmd is a child of the "auth" command.
 func isAuthCommand(cmd *cobra.Command) bool {
 	if cmd.Name() == "auth" {
@@ -1,122 +1,359 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/jobs"
 	"github.com/the20100/fal-cli/internal/output"
 )
 
 var runInputFlag string
-var runQueueFlag bool
-var runLogsFlag bool
+var runSetFlags []string
+var runUpload uploadOptions
+var runNoValidate bool
+var runSubmit submitOptions
 
 var runCmd = &cobra.Command{
-	Use:   "run <model-id>",
+	Use:   "run <model-id> [--<parameter> value ...]",
 	Short: "Run a model (synchronous by default, use --queue for async)",
-	Long: `Run a fal.ai model with a JSON input payload.
+	Long: `Run a fal.ai model with a JSON or YAML input payload, or with flags
+generated from the model's input schema.
+
+Every model parameter is also a flag: num_images becomes --num-images.
+Run "fal run <model-id> --help" to list a model's parameters with their
+types, defaults and allowed values. Parameter flags go after the model ID.
+
+--input accepts inline JSON/YAML, @file (JSON or YAML) or - for stdin.
+--set key.path=value (repeatable) overrides fields on top of it and of
+parameter flags; values are parsed as JSON when possible (numbers,
+booleans, arrays, objects), otherwise kept as strings.
+
+Local files are uploaded before submission: any string "@file:<path>", and
+any bare path to an existing file in a field ending in _url or _urls, is
+replaced with a fal storage URL (or a data URI with --upload-mode datauri).
+
+The payload is checked against the model's OpenAPI input schema (fetched
+once a day and cached) before anything is uploaded or submitted: unknown
+fields, wrong types, enum values and out-of-range numbers are reported.
+--no-validate skips the check.
 
 By default runs synchronously (connection stays open until result).
-Use --queue to submit to the queue and poll until completion.
+Use --queue to submit to the queue and poll until completion. Pressing
+Ctrl-C while polling cancels the queued request before exiting.
+
+Queued requests that fail, are cancelled, or exceed --timeout exit with
+distinct codes: 2 = failed, 3 = cancelled, 4 = timed out (130 = interrupted).
 
 Examples:
   fal run fal-ai/nano-banana-pro --input '{"prompt":"a cat"}'
+  fal run fal-ai/flux/dev --prompt "a cat" --num-images 2 --image-size landscape_4_3
+  fal run fal-ai/flux/dev --help
   fal run fal-ai/flux/dev --input '{"prompt":"a cat"}' --queue
-  fal run fal-ai/nano-banana-pro/edit --input '{"prompt":"make it night","image_urls":["https://..."]}'`,
-	Args: cobra.ExactArgs(1),
-	RunE: runRunCmd,
+  fal run fal-ai/flux/dev --input '{"prompt":"a cat"}' --queue --timeout 5m
+  fal run fal-ai/flux/dev --input '{"prompt":"a cat","num_images":4}' -o ./out
+  fal run fal-ai/nano-banana-pro/edit --input '{"prompt":"make it night","image_urls":["https://..."]}'
+  fal run fal-ai/flux/dev --input @base.yaml --set prompt="a red fox" --set num_images=2
+  cat payload.json | fal run fal-ai/flux/dev --input - --set image_size.width=1024
+  fal run fal-ai/kling-video/v2/image-to-video --set prompt="pan left" --set image_url=./photo.jpg`,
+	// Flags depend on the model, so run parses them itself (see runRunCmd).
+	DisableFlagParsing: true,
+	RunE:               runRunCmd,
+	ValidArgsFunction:  completeRunArgs,
 }
 
 func init() {
-	runCmd.Flags().StringVar(&runInputFlag, "input", "", "JSON input payload (required)")
-	runCmd.Flags().BoolVar(&runQueueFlag, "queue", false, "Use the queue (async) instead of sync")
-	runCmd.Flags().BoolVar(&runLogsFlag, "logs", false, "Show model logs while polling queue (implies --queue)")
-	_ = runCmd.MarkFlagRequired("input")
+	runCmd.Flags().StringVar(&runInputFlag, "input", "", "Input payload: inline JSON/YAML, @file, or - for stdin")
+	runCmd.Flags().StringArrayVar(&runSetFlags, "set", nil, "Override a payload field: key.path=value (repeatable)")
+	addUploadFlags(runCmd, &runUpload, uploadModeFal)
+	runCmd.Flags().BoolVar(&runNoValidate, "no-validate", false, "Send the payload without checking it against the model's input schema")
+	addSubmitFlags(runCmd, &runSubmit)
 	rootCmd.AddCommand(runCmd)
 }
 
+// submitOptions holds the flags shared by run and the generate/edit
+// shortcuts that choose how a request is submitted.
+type submitOptions struct {
+	queue    bool
+	logs     bool
+	timeout  time.Duration
+	dryRun   bool
+	download downloadOptions
+
+	allowOverBudget bool
+}
+
+// addSubmitFlags registers --queue, --logs, --timeout, --dry-run,
+// --allow-over-budget and the --download flags on cmd.
+func addSubmitFlags(cmd *cobra.Command, o *submitOptions) {
+	cmd.Flags().BoolVar(&o.queue, "queue", false,
+		"Submit via queue instead of sync")
+	cmd.Flags().BoolVar(&o.logs, "logs", false,
+		"Show model logs while polling queue (implies --queue)")
+	cmd.Flags().DurationVar(&o.timeout, "timeout", 0,
+		"Cancel the queued request if not finished after this long, e.g. 10m (implies --queue)")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false,
+		"Print the payload, endpoint and estimated cost without submitting or uploading anything")
+	cmd.Flags().BoolVar(&o.allowOverBudget, "allow-over-budget", false,
+		"Submit even if the estimated cost exceeds a budget cap (see fal budget)")
+	addDownloadFlags(cmd, &o.download)
+}
+
+// useQueue reports whether the request goes through the queue.
+func (o *submitOptions) useQueue() bool {
+	return o.queue || o.logs || o.timeout > 0
+}
+
+// submit runs payload against modelID, via the queue or synchronously
+// depending on o. Local files in payload are uploaded as u says (nil: none
+// to upload) once the request is within budget, so a refused request
+// uploads nothing.
+func submit(cmd *cobra.Command, modelID string, payload map[string]any, o *submitOptions, u *uploadOptions) error {
+	if o.dryRun {
+		return printDryRun(cmd, modelID, payload, o)
+	}
+	refund, err := chargeBudget(cmd, modelID, payload, o.allowOverBudget)
+	if err != nil {
+		return err
+	}
+	if u != nil {
+		if err := uploadLocalRefs(cmd, payload, *u); err != nil {
+			refund()
+			return err
+		}
+	}
+	if o.useQueue() {
+		return runViaQueue(cmd, modelID, payload, o, refund)
+	}
+	return runViaSync(cmd, modelID, payload, o, refund)
+}
+
+// runRunCmd parses run's flags in two passes: a first scan finds the model
+// ID and the global flags needed to create the client, then the model's
+// schema is loaded, its parameter flags are added, and the arguments are
+// parsed for real.
 func runRunCmd(cmd *cobra.Command, args []string) error {
-	modelID := args[0]
+	modelID, help, err := scanRunArgs(cmd, args)
+	if err != nil {
+		return err
+	}
+	if modelID == "" {
+		if help {
+			return cmd.Help()
+		}
+		return fmt.Errorf("a model ID is required: fal run <model-id> [flags]")
+	}
 
-	var payload map[string]any
-	if err := json.Unmarshal([]byte(runInputFlag), &payload); err != nil {
-		return fmt.Errorf("invalid --input JSON: %w", err)
+	// Help is still shown, without parameters unless cached, when setup fails.
+	setupErr := setup(cmd)
+	if setupErr != nil && !help {
+		return setupErr
+	}
+
+	static := cmd.LocalNonPersistentFlags()
+	ep, schemaErr := loadSchema(cmd.Context(), modelID, false)
+	var params *pflag.FlagSet
+	if schemaErr == nil {
+		params = addParamFlags(cmd, ep)
+	}
+	if err := cmd.Flags().Parse(args); err != nil {
+		if name, ok := strings.CutPrefix(err.Error(), "unknown flag: --"); ok {
+			if schemaErr != nil {
+				return fmt.Errorf("%w (model parameter flags are unavailable: %s; use --set instead)", err, schemaErr)
+			}
+			if guess := suggestFlag(cmd, name); guess != "" {
+				return fmt.Errorf("%w (did you mean --%s?)", err, guess)
+			}
+		}
+		return err
+	}
+	if help {
+		return printRunHelp(cmd, modelID, static, params, schemaErr)
+	}
+	if n := cmd.Flags().NArg(); n != 1 {
+		return fmt.Errorf("expected a single model ID, got %d arguments: %s", n, strings.Join(cmd.Flags().Args(), " "))
 	}
 
-	if runQueueFlag || runLogsFlag {
-		return runViaQueue(cmd, modelID, payload, runLogsFlag)
+	values := paramValues(params)
+	if runInputFlag == "" && len(runSetFlags) == 0 && len(values) == 0 {
+		return fmt.Errorf("an input payload is required: --input, --set or parameter flags (see fal run %s --help)", modelID)
 	}
-	return runViaSync(cmd, modelID, payload)
+	payload, err := parsePayload(runInputFlag, nil)
+	if err != nil {
+		return err
+	}
+	for field, v := range values {
+		payload[field] = v
+	}
+	if err := applySets(payload, runSetFlags); err != nil {
+		return err
+	}
+	if !runNoValidate {
+		if schemaErr != nil {
+			warnNoSchema(schemaErr)
+		}
+		if err := validatePayload(modelID, ep, payload); err != nil {
+			return err
+		}
+	}
+	return submit(cmd, modelID, payload, &runSubmit, &runUpload)
 }
 
-func runViaSync(cmd *cobra.Command, modelID string, payload map[string]any) error {
-	body, err := client.RunSync(modelID, payload)
+// runViaSync and runViaQueue call refund when the request fails to go
+// through, so it is not counted against the budget.
+func runViaSync(cmd *cobra.Command, modelID string, payload map[string]any, o *submitOptions, refund func()) error {
+	submitted := time.Now().UTC()
+	body, requestID, err := client.RunSync(cmd.Context(), modelID, payload)
+	if requestID == "" {
+		requestID = localRequestID()
+	}
+
+	j := jobs.Job{
+		RequestID:   requestID,
+		ModelID:     modelID,
+		Mode:        "sync",
+		Status:      api.StatusCompleted,
+		Payload:     payload,
+		Outputs:     resultURLs(body),
+		SubmittedAt: submitted,
+	}
 	if err != nil {
+		j.Status = jobs.StatusFailed
+		j.Error = err.Error()
+		refund()
+	}
+	recordJob(j)
+
+	if err != nil {
+		return err
+	}
+	if err := printResult(cmd, body); err != nil {
 		return err
 	}
-	return printResult(cmd, body)
+	return saveOutputs(cmd.Context(), cmd, &o.download, modelID, requestID, payload, body)
 }
 
-func runViaQueue(cmd *cobra.Command, modelID string, payload map[string]any, withLogs bool) error {
-	sub, err := client.QueueSubmit(modelID, payload)
+func runViaQueue(cmd *cobra.Command, modelID string, payload map[string]any, o *submitOptions, refund func()) error {
+	sub, err := client.QueueSubmit(cmd.Context(), modelID, payload)
 	if err != nil {
+		refund()
 		return err
 	}
 	fmt.Fprintf(os.Stderr, "Queued: %s\n", sub.RequestID)
+	recordJob(jobs.Job{
+		RequestID: sub.RequestID,
+		ModelID:   modelID,
+		Mode:      "queue",
+		Status:    api.StatusInQueue,
+		Payload:   payload,
+	})
 
-	result, err := pollQueueUntilDone(modelID, sub.RequestID, withLogs)
+	result, err := pollQueueUntilDone(cmd.Context(), modelID, sub.RequestID, o.logs, o.timeout)
+	finishJob(sub.RequestID, result, err)
 	if err != nil {
 		return err
 	}
-	return printResult(cmd, result)
+	if err := printResult(cmd, result); err != nil {
+		return err
+	}
+	return saveOutputs(cmd.Context(), cmd, &o.download, modelID, sub.RequestID, payload, result)
 }
 
-// pollQueueUntilDone polls queue status until COMPLETED and returns the result bytes.
-func pollQueueUntilDone(modelID, requestID string, withLogs bool) ([]byte, error) {
+// errTimedOut is wrapped by the error returned when --timeout expires.
+var errTimedOut = errors.New("timed out")
+
+// pollQueueUntilDone polls queue status until the request reaches a terminal
+// status and returns the result bytes, reporting progress on stderr.
+//
+// If ctx is cancelled (Ctrl-C) or timeout elapses first, the request is
+// cancelled on the queue before returning. Failed or cancelled requests
+// return an *api.QueueError after their logs are printed to stderr.
+func pollQueueUntilDone(ctx context.Context, modelID, requestID string, withLogs bool, timeout time.Duration) ([]byte, error) {
 	seenLogs := map[string]bool{}
-	delay := time.Second
-	maxDelay := 10 * time.Second
-
-	for {
-		time.Sleep(delay)
-		if delay < maxDelay {
-			delay *= 2
-			if delay > maxDelay {
-				delay = maxDelay
+	printLogs := func(logs []api.LogEntry) {
+		for _, log := range logs {
+			key := log.Timestamp + log.Message
+			if !seenLogs[key] {
+				seenLogs[key] = true
+				fmt.Fprintf(os.Stderr, "[%s] %s\n", log.Level, log.Message)
 			}
 		}
+	}
 
-		status, err := client.QueueStatus(modelID, requestID, withLogs)
-		if err != nil {
-			return nil, err
-		}
-
+	result, err := waitQueued(ctx, modelID, requestID, withLogs, timeout, func(status *api.QueueStatus) {
 		if withLogs {
-			for _, log := range status.Logs {
-				key := log.Timestamp + log.Message
-				if !seenLogs[key] {
-					seenLogs[key] = true
-					fmt.Fprintf(os.Stderr, "[%s] %s\n", log.Level, log.Message)
-				}
-			}
+			printLogs(status.Logs)
 		}
 
 		switch status.Status {
-		case "COMPLETED":
-			return client.QueueResult(modelID, requestID)
-		case "IN_QUEUE":
+		case api.StatusInQueue:
 			if status.QueuePosition != nil {
 				fmt.Fprintf(os.Stderr, "Queue position: %d\n", *status.QueuePosition)
 			} else {
 				fmt.Fprintf(os.Stderr, "In queue...\n")
 			}
-		case "IN_PROGRESS":
+		case api.StatusInProgress:
 			fmt.Fprintf(os.Stderr, "In progress...\n")
 		}
+	})
+
+	var qe *api.QueueError
+	if errors.As(err, &qe) && len(qe.Logs) > 0 {
+		if !withLogs {
+			fmt.Fprintln(os.Stderr, "\nLogs:")
+		}
+		printLogs(qe.Logs)
+	}
+	return result, err
+}
+
+// waitQueued waits for requestID like pollQueueUntilDone, calling progress
+// (if not nil) on each status instead of printing it. The request is
+// cancelled on the queue when ctx is done or timeout elapses.
+func waitQueued(ctx context.Context, modelID, requestID string, withLogs bool, timeout time.Duration, progress func(*api.QueueStatus)) ([]byte, error) {
+	waitCtx := ctx
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	result, err := client.QueueWait(waitCtx, modelID, requestID, withLogs, progress)
+	switch {
+	case err == nil:
+		return result, nil
+	case ctx.Err() != nil:
+		fmt.Fprintf(os.Stderr, "\nInterrupted — cancelling %s...\n", requestID)
+		cancelQueued(modelID, requestID)
+		return nil, fmt.Errorf("interrupted: %w", ctx.Err())
+	case waitCtx.Err() != nil:
+		fmt.Fprintf(os.Stderr, "\nTimed out after %s — cancelling %s...\n", timeout, requestID)
+		cancelQueued(modelID, requestID)
+		return nil, fmt.Errorf("request %s %w after %s", requestID, errTimedOut, timeout)
+	}
+	return nil, err
+}
+
+// cancelQueued asks the queue to cancel requestID and reports the outcome on
+// stderr. It uses its own short-lived context because it usually runs after
+// the command's context is already done.
+func cancelQueued(modelID, requestID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), api.CancelTimeout)
+	defer cancel()
+	if err := client.QueueCancel(ctx, modelID, requestID); err != nil {
+		fmt.Fprintf(os.Stderr, "Cancel failed: %s\n", err)
+		fmt.Fprintf(os.Stderr, "The request may still be running: fal queue cancel %s %s\n", modelID, requestID)
+		return
 	}
+	fmt.Fprintf(os.Stderr, "Cancellation requested for: %s\n", requestID)
 }
 
 // printResult writes result body to stdout in the right format.
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/schema"
+)
+
+// loadSchema returns the schemas of modelID, parsed from the OpenAPI
+// document returned by loadSchemaDoc.
+func loadSchema(ctx context.Context, modelID string, refresh bool) (*schema.Endpoint, error) {
+	raw, err := loadSchemaDoc(ctx, modelID, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return schema.Parse(modelID, raw)
+}
+
+// loadSchemaDoc returns the OpenAPI document of modelID. It is read from
+// the local cache while it is younger than schema.MaxAge, and fetched
+// otherwise (always, with refresh). A stale cached copy is used when the
+// fetch fails.
+func loadSchemaDoc(ctx context.Context, modelID string, refresh bool) ([]byte, error) {
+	raw, fetchedAt, cacheErr := schema.Load(modelID)
+	if cacheErr == nil && !refresh && time.Since(fetchedAt) < schema.MaxAge {
+		return raw, nil
+	}
+	if client == nil {
+		if cacheErr == nil {
+			return raw, nil
+		}
+		return nil, fmt.Errorf("no cached schema for %s", modelID)
+	}
+
+	m, err := client.GetModel(ctx, modelID, true)
+	if err == nil && len(m.OpenAPI) == 0 {
+		err = fmt.Errorf("no OpenAPI schema published for %s", modelID)
+	}
+	if err != nil {
+		if cacheErr == nil && ctx.Err() == nil {
+			return raw, nil
+		}
+		return nil, err
+	}
+	if err := schema.Store(modelID, m.OpenAPI); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not cache schema: %s\n", err)
+	}
+	return m.OpenAPI, nil
+}
+
+// warnNoSchema reports that validation is skipped because the schema could
+// not be loaded; a missing schema never blocks a request.
+func warnNoSchema(err error) {
+	fmt.Fprintf(os.Stderr, "Warning: skipping input validation: %s\n", err)
+}
+
+// validatePayload checks payload against the input schema of modelID. A nil
+// ep (schema unavailable) accepts anything.
+func validatePayload(modelID string, ep *schema.Endpoint, payload map[string]any) error {
+	if ep == nil {
+		return nil
+	}
+	problems := ep.Validate(payload)
+	if len(problems) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "payload does not match the input schema of %s:", modelID)
+	for _, p := range problems {
+		b.WriteString("\n  " + p.String())
+	}
+	b.WriteString("\n(use --no-validate to send it anyway)")
+	return errors.New(b.String())
+}
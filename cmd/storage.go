@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/config"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/storage"
+	"github.com/the20100/fal-cli/internal/uploadcache"
+)
+
+var storageCmd = &cobra.Command{
+	Use:         "storage",
+	Short:       "Manage files uploaded to your S3-compatible bucket",
+	Annotations: map[string]string{annotationOffline: "true"},
+	Long: `Local files sent with --upload-mode s3 are uploaded to your own
+S3-compatible bucket (Cloudflare R2, AWS S3, MinIO, ...) under a key
+derived from their SHA-256, so different files never overwrite each other.
+
+The bucket is configured in the config file:
+
+  "storage": {
+    "endpoint": "https://<account-id>.r2.cloudflarestorage.com",
+    "bucket": "my-pub",
+    "access_key_id": "...",
+    "secret_access_key": "...",
+    "public_url": "https://pub.example.com"
+  }
+
+or with env vars, which take precedence: FAL_S3_ENDPOINT, FAL_S3_REGION,
+FAL_S3_BUCKET, FAL_S3_ACCESS_KEY_ID (or AWS_ACCESS_KEY_ID),
+FAL_S3_SECRET_ACCESS_KEY (or AWS_SECRET_ACCESS_KEY), FAL_S3_PUBLIC_URL and
+FAL_S3_PREFIX (default "fal-tmp/"). The region defaults to "auto" for R2
+and us-east-1 elsewhere. The bucket must be publicly readable at
+public_url so models can fetch the files.`,
+}
+
+var storageCleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Delete uploaded files older than a given age",
+	Long: `Delete uploaded files older than --older-than from the bucket. Only keys
+under the upload prefix are considered.
+
+Examples:
+  fal storage cleanup --dry-run
+  fal storage cleanup --older-than 30d`,
+	Args: cobra.NoArgs,
+	RunE: runStorageCleanup,
+}
+
+var (
+	storageOlderThan string
+	storageDryRun    bool
+)
+
+func init() {
+	storageCleanupCmd.Flags().StringVar(&storageOlderThan, "older-than", "7d",
+		"Minimum age of files to delete (e.g. 12h, 7d)")
+	storageCleanupCmd.Flags().BoolVar(&storageDryRun, "dry-run", false,
+		"List the files that would be deleted without deleting them")
+
+	storageCmd.AddCommand(storageCleanupCmd)
+	rootCmd.AddCommand(storageCmd)
+}
+
+// resolveStorage builds a storage client from env vars and the config
+// file. A non-empty bucket or publicURL (from flags) overrides both.
+func resolveStorage(bucket, publicURL string) (*storage.Client, error) {
+	c := config.Storage{}
+	if cfg != nil && cfg.Storage != nil {
+		c = *cfg.Storage
+	}
+	pick := func(flag string, configured string, envVars ...string) string {
+		if flag != "" {
+			return flag
+		}
+		if v := resolveEnv(envVars...); v != "" {
+			return v
+		}
+		return configured
+	}
+	return storage.New(storage.Config{
+		Endpoint:        pick("", c.Endpoint, "FAL_S3_ENDPOINT"),
+		Region:          pick("", c.Region, "FAL_S3_REGION", "AWS_REGION"),
+		Bucket:          pick(bucket, c.Bucket, "FAL_S3_BUCKET"),
+		AccessKeyID:     pick("", c.AccessKeyID, "FAL_S3_ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: pick("", c.SecretAccessKey, "FAL_S3_SECRET_ACCESS_KEY", "AWS_SECRET_ACCESS_KEY"),
+		PublicURL:       pick(publicURL, c.PublicURL, "FAL_S3_PUBLIC_URL"),
+		Prefix:          pick("", c.Prefix, "FAL_S3_PREFIX"),
+	})
+}
+
+func runStorageCleanup(cmd *cobra.Command, args []string) error {
+	age, err := parseAge(storageOlderThan)
+	if err != nil {
+		return err
+	}
+	s, err := resolveStorage("", "")
+	if err != nil {
+		return err
+	}
+
+	objects, err := s.List(cmd.Context(), s.Prefix())
+	if err != nil {
+		return err
+	}
+	cutoff := time.Now().Add(-age)
+	var old []storage.Object
+	for _, o := range objects {
+		if o.LastModified.Before(cutoff) {
+			old = append(old, o)
+		}
+	}
+
+	var deleted []string
+	var failed int
+	for _, o := range old {
+		if storageDryRun {
+			deleted = append(deleted, o.Key)
+			continue
+		}
+		if err := s.Delete(cmd.Context(), o.Key); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  ✗ %s: %s\n", o.Key, err)
+			failed++
+			continue
+		}
+		deleted = append(deleted, o.Key)
+	}
+	if !storageDryRun && len(deleted) > 0 {
+		if err := uploadcache.Forget(uploadModeS3+":"+s.Bucket(), deleted); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Warning: could not update upload cache: %s\n", err)
+		}
+	}
+
+	if output.IsJSON(cmd) {
+		if deleted == nil {
+			deleted = []string{}
+		}
+		if err := output.PrintJSON(map[string]any{
+			"bucket":  s.Bucket(),
+			"dry_run": storageDryRun,
+			"deleted": deleted,
+			"kept":    len(objects) - len(old),
+		}, output.IsPretty(cmd)); err != nil {
+			return err
+		}
+	} else {
+		verb := "Deleted"
+		if storageDryRun {
+			verb = "Would delete"
+		}
+		for _, k := range deleted {
+			fmt.Printf("  %s\n", k)
+		}
+		fmt.Printf("%s %d of %d file(s) in %s/%s older than %s.\n",
+			verb, len(deleted), len(objects), s.Bucket(), s.Prefix(), storageOlderThan)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d file(s) could not be deleted", failed)
+	}
+	return nil
+}
+
+// parseAge parses a duration that may also be given in days ("7d").
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid age %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 12h or 7d)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,441 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/config"
+	"github.com/the20100/fal-cli/internal/output"
+	"github.com/the20100/fal-cli/internal/usage"
+)
+
+// maxSweepCombinations bounds the size of a sweep, so a typo in a range
+// (seed=1..10000) does not submit thousands of requests.
+const maxSweepCombinations = 1000
+
+var sweepCmd = &cobra.Command{
+	Use:   "sweep <model-id> --vary key=values...",
+	Short: "Run a payload over every combination of parameter values",
+	Long: `Expand a base payload into the cartesian product of the --vary values and
+run every combination through the queue, like "fal batch".
+
+--vary takes key=values, where key is a payload field (dotted paths work as
+for --set) and values is either a comma-separated list or an integer range
+a..b (inclusive). List values are parsed as JSON when possible, as for --set;
+quote a value that contains a comma, as a JSON string:
+--vary 'prompt="a fox, watercolor","a fox, ink"'. JSON arrays and objects
+are kept whole: --vary 'image_size={"width":512,"height":512},square'.
+
+Each finished combination is appended to the manifest (--manifest, JSONL;
+by default a new sweep-<model>-<time>.jsonl per run) with its varied values
+("params"), request ID, output URLs and result or error, so outputs can be
+traced back to the settings that produced them. --resume, given the
+--manifest of an earlier run, skips combinations already COMPLETED in it.
+
+--dry-run lists the combinations with their estimated cost and submits
+nothing. At most 1000 combinations are allowed.
+
+generate and generate-banana accept --vary too, with their own flag names
+as keys.
+
+Examples:
+  fal sweep openai/gpt-image-2 --input base.json --vary quality=low,medium,high --vary num_images=1..2
+  fal sweep fal-ai/flux/dev --set prompt="a red fox" --vary seed=1..8 --vary image_size=square,landscape_4_3 -o ./sweep
+  fal sweep fal-ai/flux/dev --input base.json --vary seed=1..8 --dry-run
+  fal generate-banana "a cat" --vary seed=1..4 --vary resolution=1K,2K --manifest cat.jsonl`,
+	Args:              cobra.ExactArgs(1),
+	RunE:              runSweep,
+	ValidArgsFunction: completeModelID,
+}
+
+var (
+	sweepInputFlag string
+	sweepSetFlags  []string
+	sweepVary      sweepOptions
+	sweepDryRun    bool
+	sweepOpts      batchOptions
+)
+
+// sweepOptions holds the flags that turn a command into a sweep.
+type sweepOptions struct {
+	vary        []string
+	manifest    string
+	concurrency int
+	resume      bool
+}
+
+// addSweepFlags registers --vary, --manifest, --concurrency and --resume
+// on cmd.
+func addSweepFlags(cmd *cobra.Command, o *sweepOptions) {
+	cmd.Flags().StringArrayVar(&o.vary, "vary", nil,
+		"Run every combination of values: key=a,b,c or key=1..8 (repeatable; see fal sweep --help)")
+	cmd.Flags().StringVar(&o.manifest, "manifest", "",
+		"With --vary, JSONL file mapping each combination to its request and outputs (default sweep-<model>-<time>.jsonl)")
+	cmd.Flags().IntVarP(&o.concurrency, "concurrency", "c", 4,
+		"With --vary, max requests in flight at once")
+	cmd.Flags().BoolVar(&o.resume, "resume", false,
+		"With --vary, skip combinations already completed in --manifest and append to it")
+}
+
+// manifestPath returns the --manifest file, or a new name derived from
+// modelID and the time, so sweeps run in the same directory never share a
+// manifest. --resume needs the manifest of the sweep to continue.
+func (o *sweepOptions) manifestPath(modelID string) (string, error) {
+	switch {
+	case o.manifest != "":
+		return o.manifest, nil
+	case o.resume:
+		return "", fmt.Errorf("--resume needs --manifest: the manifest of the sweep to continue")
+	}
+	model := strings.ReplaceAll(strings.Trim(modelID, "/"), "/", "-")
+	return fmt.Sprintf("sweep-%s-%s.jsonl", model, time.Now().Format("20060102-150405")), nil
+}
+
+func init() {
+	sweepCmd.Flags().StringVar(&sweepInputFlag, "input", "", "Base payload: inline JSON/YAML, @file, or - for stdin")
+	sweepCmd.Flags().StringArrayVar(&sweepSetFlags, "set", nil, "Override a base payload field: key.path=value (repeatable)")
+	addSweepFlags(sweepCmd, &sweepVary)
+	sweepCmd.Flags().DurationVar(&sweepOpts.timeout, "timeout", 0, "Cancel a request if not finished after this long, e.g. 10m (0 = no limit)")
+	sweepCmd.Flags().BoolVar(&sweepOpts.noValidate, "no-validate", false, "Send payloads without checking them against the model's input schema")
+	sweepCmd.Flags().BoolVar(&sweepOpts.allowOverBudget, "allow-over-budget", false, "Keep submitting even if a budget cap is exceeded")
+	sweepCmd.Flags().BoolVar(&sweepDryRun, "dry-run", false, "List the combinations and their estimated cost without submitting anything")
+	addUploadFlags(sweepCmd, &sweepOpts.upload, uploadModeFal)
+	addDownloadFlags(sweepCmd, &sweepOpts.download)
+	_ = sweepCmd.MarkFlagRequired("vary")
+	rootCmd.AddCommand(sweepCmd)
+}
+
+// sweepAxis is one --vary flag: a key and the values it takes.
+type sweepAxis struct {
+	key    string
+	values []any
+}
+
+// parseVary parses a --vary key=values flag. Values are an integer range
+// a..b or a comma-separated list, each item parsed as a --set value. Commas
+// inside a JSON string ("a fox, watercolor"), array or object do not split
+// the list.
+func parseVary(s string) (sweepAxis, error) {
+	key, raw, ok := strings.Cut(s, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" || raw == "" {
+		return sweepAxis{}, fmt.Errorf("invalid --vary %q: expected key=a,b,c or key=1..8", s)
+	}
+	ax := sweepAxis{key: key}
+
+	if lo, hi, ok := strings.Cut(raw, ".."); ok && isRangeStart(raw) {
+		from, err1 := strconv.ParseInt(strings.TrimSpace(lo), 10, 64)
+		to, err2 := strconv.ParseInt(strings.TrimSpace(hi), 10, 64)
+		if err1 != nil || err2 != nil {
+			return sweepAxis{}, fmt.Errorf("invalid --vary %q: a range must be two integers, e.g. 1..8", s)
+		}
+		if from > to {
+			return sweepAxis{}, fmt.Errorf("invalid --vary %q: range %d..%d is empty", s, from, to)
+		}
+		if to-from < 0 || to-from >= maxSweepCombinations { // < 0: the width overflowed
+			return sweepAxis{}, fmt.Errorf("invalid --vary %q: more than %d values", s, maxSweepCombinations)
+		}
+		for n := from; n <= to; n++ {
+			ax.values = append(ax.values, n)
+		}
+		return ax, nil
+	}
+
+	items, err := splitVaryList(raw)
+	if err != nil {
+		return sweepAxis{}, fmt.Errorf("invalid --vary %q: %w", s, err)
+	}
+	for _, item := range items {
+		ax.values = append(ax.values, parseSetValue(strings.TrimSpace(item)))
+	}
+	return ax, nil
+}
+
+// isRangeStart reports whether raw looks like an integer range rather than
+// a list item that happens to contain "..".
+func isRangeStart(raw string) bool {
+	raw = strings.TrimSpace(raw)
+	return raw != "" && (raw[0] == '-' || raw[0] == '+' || ('0' <= raw[0] && raw[0] <= '9'))
+}
+
+// splitVaryList splits a --vary list on the commas that are outside JSON
+// strings, arrays and objects.
+func splitVaryList(raw string) ([]string, error) {
+	var items []string
+	depth, inString, escaped, start := 0, false, false, 0
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case inString && escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, raw[start:i])
+			start = i + 1
+		}
+	}
+	if inString || depth != 0 {
+		return nil, fmt.Errorf("unbalanced quotes or brackets")
+	}
+	return append(items, raw[start:]), nil
+}
+
+// parseVaryFlags parses every --vary flag; a key may only be varied once.
+func parseVaryFlags(specs []string) ([]sweepAxis, error) {
+	var axes []sweepAxis
+	seen := map[string]bool{}
+	total := 1
+	for _, s := range specs {
+		ax, err := parseVary(s)
+		if err != nil {
+			return nil, err
+		}
+		if seen[ax.key] {
+			return nil, fmt.Errorf("--vary %s is given more than once", ax.key)
+		}
+		seen[ax.key] = true
+		total *= len(ax.values)
+		if total > maxSweepCombinations {
+			return nil, fmt.Errorf("the sweep has more than %d combinations", maxSweepCombinations)
+		}
+		axes = append(axes, ax)
+	}
+	return axes, nil
+}
+
+// combinations returns the cartesian product of axes, the last axis
+// varying fastest. Each combination maps keys to values.
+func combinations(axes []sweepAxis) []map[string]any {
+	combos := []map[string]any{{}}
+	for _, ax := range axes {
+		next := make([]map[string]any, 0, len(combos)*len(ax.values))
+		for _, c := range combos {
+			for _, v := range ax.values {
+				m := make(map[string]any, len(c)+1)
+				for k, cv := range c {
+					m[k] = cv
+				}
+				m[ax.key] = v
+				next = append(next, m)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// sweepLine turns a combination and its payload into a batch line, keyed
+// by the hash of the payload so --resume only skips identical requests.
+func sweepLine(num int, params, payload map[string]any) batchLine {
+	l := batchLine{num: num, params: params, payload: payload}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		l.err = err
+		return l
+	}
+	h := sha256.Sum256(data)
+	l.hash = hex.EncodeToString(h[:])
+	return l
+}
+
+func runSweep(cmd *cobra.Command, args []string) error {
+	modelID := args[0]
+	axes, err := parseVaryFlags(sweepVary.vary)
+	if err != nil {
+		return err
+	}
+	base, err := parsePayload(sweepInputFlag, sweepSetFlags)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(base)
+	if err != nil {
+		return err
+	}
+
+	var lines []batchLine
+	for i, params := range combinations(axes) {
+		// Decode the base afresh for each combination, so setPath never
+		// shares nested objects between payloads.
+		var payload map[string]any
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&payload); err != nil {
+			return err
+		}
+		for _, ax := range axes {
+			if err := setPath(payload, strings.Split(ax.key, "."), params[ax.key]); err != nil {
+				return fmt.Errorf("invalid --vary %s: %w", ax.key, err)
+			}
+		}
+		lines = append(lines, sweepLine(i+1, params, payload))
+	}
+
+	if sweepDryRun {
+		return printSweepDryRun(cmd, modelID, lines)
+	}
+	o := sweepOpts
+	o.concurrency, o.resume = sweepVary.concurrency, sweepVary.resume
+	if o.out, err = sweepVary.manifestPath(modelID); err != nil {
+		return err
+	}
+	if o.concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	return runQueuedLines(cmd, modelID, lines, &o)
+}
+
+// runShortcutSweep runs a generate shortcut once per combination of its
+// --vary flags. Keys are the shortcut's own flags; for each combination
+// they are set on cmd and payload rebuilds the request from them.
+func runShortcutSweep(cmd *cobra.Command, modelID string, v *sweepOptions, s *submitOptions, payload func() map[string]any) error {
+	axes, err := parseVaryFlags(v.vary)
+	if err != nil {
+		return err
+	}
+	for _, ax := range axes {
+		if !isSweepableFlag(cmd, ax.key) {
+			return fmt.Errorf("--vary %s: not a parameter of %s", ax.key, cmd.CommandPath())
+		}
+	}
+
+	var lines []batchLine
+	for i, params := range combinations(axes) {
+		for _, ax := range axes {
+			if err := cmd.Flags().Set(ax.key, fmt.Sprint(params[ax.key])); err != nil {
+				return fmt.Errorf("invalid --vary %s: %w", ax.key, err)
+			}
+		}
+		lines = append(lines, sweepLine(i+1, params, payload()))
+	}
+
+	if s.dryRun {
+		return printSweepDryRun(cmd, modelID, lines)
+	}
+	manifest, err := v.manifestPath(modelID)
+	if err != nil {
+		return err
+	}
+	o := &batchOptions{
+		out:             manifest,
+		concurrency:     v.concurrency,
+		resume:          v.resume,
+		timeout:         s.timeout,
+		noValidate:      true, // the shortcuts send what their flags allow
+		allowOverBudget: s.allowOverBudget,
+		download:        s.download,
+	}
+	if o.concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	return runQueuedLines(cmd, modelID, lines, o)
+}
+
+// isSweepableFlag reports whether name is one of cmd's model parameters,
+// as opposed to the flags shared by every shortcut (submit, download and
+// sweep flags).
+func isSweepableFlag(cmd *cobra.Command, name string) bool {
+	if cmd.Flags().Lookup(name) == nil {
+		return false
+	}
+	shared := &cobra.Command{}
+	addSubmitFlags(shared, &submitOptions{})
+	addSweepFlags(shared, &sweepOptions{})
+	return shared.Flags().Lookup(name) == nil
+}
+
+// sweepPlan is one combination in the --dry-run output of a sweep.
+type sweepPlan struct {
+	Num           int            `json:"num"`
+	Params        map[string]any `json:"params"`
+	Payload       map[string]any `json:"payload"`
+	Estimate      *costEstimate  `json:"estimate"`
+	EstimateError string         `json:"estimate_error,omitempty"`
+}
+
+// printSweepDryRun lists the requests of a sweep or bulk edit with their
+// estimated cost, and the total, without submitting anything.
+func printSweepDryRun(cmd *cobra.Command, modelID string, lines []batchLine) error {
+	res := struct {
+		DryRun      bool        `json:"dry_run"`
+		ModelID     string      `json:"model_id"`
+		Total       *float64    `json:"total,omitempty"`
+		Currency    string      `json:"currency,omitempty"`
+		BudgetError string      `json:"budget_error,omitempty"`
+		Plans       []sweepPlan `json:"combinations"`
+	}{DryRun: true, ModelID: modelID}
+
+	var total, highest float64
+	known := true
+	rows := make([][]string, 0, len(lines))
+	for _, l := range lines {
+		p := sweepPlan{Num: l.num, Params: l.params, Payload: l.payload}
+		est, err := estimateCost(cmd.Context(), modelID, l.payload)
+		if err != nil {
+			if cmd.Context().Err() != nil {
+				return err
+			}
+			p.EstimateError = err.Error()
+		}
+		p.Estimate = est
+		estimate := "unavailable"
+		if est != nil && est.Total != nil {
+			total += *est.Total
+			highest = max(highest, *est.Total)
+			res.Currency = est.Currency
+			estimate = fmt.Sprintf("%.4f %s", *est.Total, est.Currency)
+		} else {
+			known = false
+		}
+		res.Plans = append(res.Plans, p)
+		rows = append(rows, []string{strconv.Itoa(l.num), batchLabel(batchRecord{Params: l.params}), estimate})
+	}
+	if known && len(lines) > 0 {
+		// The per-command cap applies to each request, the others to the
+		// sweep as a whole.
+		res.Total = &total
+		b := currentBudget()
+		perRequest := config.Budget{PerCommand: b.PerCommand}
+		b.PerCommand = 0
+		err := usage.Check(usage.Entry{At: time.Now(), ModelID: modelID, Cost: highest}, &perRequest)
+		if err == nil {
+			err = usage.Check(usage.Entry{At: time.Now(), ModelID: modelID, Cost: total}, &b)
+		}
+		if err != nil {
+			res.BudgetError = err.Error()
+		}
+	}
+
+	if output.IsJSON(cmd) {
+		return output.PrintJSON(res, output.IsPretty(cmd))
+	}
+	output.PrintTable([]string{"#", "PARAMS", "ESTIMATE"}, rows)
+	fmt.Println()
+	totalText := "unavailable (some combinations cannot be estimated)"
+	if res.Total != nil {
+		totalText = fmt.Sprintf("%.4f %s", total, res.Currency)
+	}
+	output.PrintKeyValue([][]string{
+		{"MODEL", modelID},
+		{"REQUESTS", strconv.Itoa(len(lines))},
+		{"TOTAL", totalText},
+		{"BUDGET", res.BudgetError},
+	})
+	fmt.Println("\nDry run: nothing was submitted.")
+	return nil
+}
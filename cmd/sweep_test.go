@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVary(t *testing.T) {
+	tests := []struct {
+		spec   string
+		key    string
+		values []any
+		err    string
+	}{
+		{spec: "seed=1..4", key: "seed", values: []any{int64(1), int64(2), int64(3), int64(4)}},
+		{spec: "seed= -1 .. 1", key: "seed", values: []any{int64(-1), int64(0), int64(1)}},
+		{spec: "seed=7..7", key: "seed", values: []any{int64(7)}},
+		{spec: "seed=0..999", key: "seed", values: make([]any, 1000)},
+		{spec: "seed=0..1000", err: "more than 1000 values"},
+		{spec: "seed=-9223372036854775808..9223372036854775807", err: "more than 1000 values"},
+		{spec: "seed=5..1", err: "range 5..1 is empty"},
+		{spec: "seed=1.5..3", err: "a range must be two integers"},
+		{spec: "seed=1..", err: "a range must be two integers"},
+		{spec: "seed=-1..x", err: "a range must be two integers"},
+		{spec: "prompt=wait...,go", key: "prompt", values: []any{"wait...", "go"}},
+		{spec: `prompt="a fox, watercolor","a fox, ink"`, key: "prompt", values: []any{"a fox, watercolor", "a fox, ink"}},
+		{spec: `prompt="say \"hi, there\"",x`, key: "prompt", values: []any{`say "hi, there"`, "x"}},
+		{spec: `image_size={"width":512,"height":512},square`, key: "image_size",
+			values: []any{map[string]any{"width": float64(512), "height": float64(512)}, "square"}},
+		{spec: `loras=[{"path":"a"},{"path":"b"}],[]`, key: "loras",
+			values: []any{[]any{map[string]any{"path": "a"}, map[string]any{"path": "b"}}, []any{}}},
+		{spec: `prompt="a fox, watercolor`, err: "unbalanced quotes or brackets"},
+		{spec: `image_size={"width":512,square`, err: "unbalanced quotes or brackets"},
+		{spec: "aspect_ratio=1:1,16:9", key: "aspect_ratio", values: []any{"1:1", "16:9"}},
+		{spec: "num_images=1, 2", key: "num_images", values: []any{float64(1), float64(2)}},
+		{spec: "sync=true,false", key: "sync", values: []any{true, false}},
+		{spec: "image_size.width=512", key: "image_size.width", values: []any{float64(512)}},
+		{spec: "seed", err: "expected key=a,b,c or key=1..8"},
+		{spec: "=1,2", err: "expected key=a,b,c or key=1..8"},
+		{spec: "seed=", err: "expected key=a,b,c or key=1..8"},
+	}
+	for _, tt := range tests {
+		ax, err := parseVary(tt.spec)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseVary(%q) error = %v, want %q", tt.spec, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseVary(%q): %v", tt.spec, err)
+			continue
+		}
+		if ax.key != tt.key {
+			t.Errorf("parseVary(%q) key = %q, want %q", tt.spec, ax.key, tt.key)
+		}
+		if tt.values[0] == nil { // only the count matters
+			if len(ax.values) != len(tt.values) {
+				t.Errorf("parseVary(%q) has %d values, want %d", tt.spec, len(ax.values), len(tt.values))
+			}
+			continue
+		}
+		if !reflect.DeepEqual(ax.values, tt.values) {
+			t.Errorf("parseVary(%q) values = %#v, want %#v", tt.spec, ax.values, tt.values)
+		}
+	}
+}
+
+func TestParseVaryFlags(t *testing.T) {
+	tests := []struct {
+		specs []string
+		keys  []string
+		err   string
+	}{
+		{specs: nil},
+		{specs: []string{"seed=1..3", "aspect_ratio=1:1,16:9"}, keys: []string{"seed", "aspect_ratio"}},
+		{specs: []string{"seed=1..10", "num_images=1..100"}, keys: []string{"seed", "num_images"}},
+		{specs: []string{"seed=1..10", "num_images=1..101"}, err: "more than 1000 combinations"},
+		{specs: []string{"a=1..10", "b=1..10", "c=1..10", "d=1,2"}, err: "more than 1000 combinations"},
+		{specs: []string{"seed=1..3", "seed=4,5"}, err: "--vary seed is given more than once"},
+		{specs: []string{"seed=1..3", "bad"}, err: `invalid --vary "bad"`},
+	}
+	for _, tt := range tests {
+		axes, err := parseVaryFlags(tt.specs)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("parseVaryFlags(%q) error = %v, want %q", tt.specs, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseVaryFlags(%q): %v", tt.specs, err)
+			continue
+		}
+		var keys []string
+		for _, ax := range axes {
+			keys = append(keys, ax.key)
+		}
+		if !reflect.DeepEqual(keys, tt.keys) {
+			t.Errorf("parseVaryFlags(%q) keys = %q, want %q", tt.specs, keys, tt.keys)
+		}
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	axes := []sweepAxis{
+		{key: "seed", values: []any{int64(1), int64(2)}},
+		{key: "aspect_ratio", values: []any{"1:1", "16:9"}},
+	}
+	want := []map[string]any{
+		{"seed": int64(1), "aspect_ratio": "1:1"},
+		{"seed": int64(1), "aspect_ratio": "16:9"},
+		{"seed": int64(2), "aspect_ratio": "1:1"},
+		{"seed": int64(2), "aspect_ratio": "16:9"},
+	}
+	if got := combinations(axes); !reflect.DeepEqual(got, want) {
+		t.Errorf("combinations = %v, want %v", got, want)
+	}
+	if got := combinations(nil); len(got) != 1 || len(got[0]) != 0 {
+		t.Errorf("combinations(nil) = %v, want one empty combination", got)
+	}
+}
+
+func TestManifestPath(t *testing.T) {
+	o := &sweepOptions{manifest: "cats.jsonl"}
+	if got, err := o.manifestPath("fal-ai/flux/dev"); err != nil || got != "cats.jsonl" {
+		t.Errorf("manifestPath = %q, %v, want cats.jsonl", got, err)
+	}
+
+	o = &sweepOptions{}
+	got, err := o.manifestPath("fal-ai/flux/dev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "sweep-fal-ai-flux-dev-") || !strings.HasSuffix(got, ".jsonl") {
+		t.Errorf("default manifest = %q, want sweep-fal-ai-flux-dev-<time>.jsonl", got)
+	}
+
+	o = &sweepOptions{resume: true}
+	if _, err := o.manifestPath("fal-ai/flux/dev"); err == nil || !strings.Contains(err.Error(), "--resume needs --manifest") {
+		t.Errorf("manifestPath with --resume and no --manifest: error = %v", err)
+	}
+}
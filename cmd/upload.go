@@ -0,0 +1,300 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/storage"
+	"github.com/the20100/fal-cli/internal/uploadcache"
+)
+
+// Upload modes for local files sent to a model.
+const (
+	uploadModeAuto    = "auto"    // data URI for small files, fal storage otherwise
+	uploadModeFal     = "fal"     // upload to fal storage, send the CDN URL
+	uploadModeDataURI = "datauri" // inline as a base64 data URI
+	uploadModeS3      = "s3"      // upload to the configured S3-compatible bucket
+	uploadModeR2      = "r2"      // alias of s3
+)
+
+// dataURIMaxBytes is the largest file that auto mode inlines as a data URI.
+// Base64 adds a third to the size and the whole payload is resent on every
+// sync call, so anything bigger goes to fal storage.
+const dataURIMaxBytes = 1 << 20
+
+// progressMinBytes is the file size from which upload progress is shown.
+const progressMinBytes = 4 << 20
+
+// fileRefPrefix marks a payload string as a local file to upload.
+const fileRefPrefix = "@file:"
+
+// uploadOptions controls how local files are sent to a model.
+type uploadOptions struct {
+	mode      string
+	bucket    string // overrides the configured storage bucket
+	publicURL string // overrides the configured storage public URL
+	noCache   bool
+}
+
+// addUploadFlags registers --upload-mode and the storage flags on cmd.
+func addUploadFlags(cmd *cobra.Command, o *uploadOptions, defaultMode string) {
+	cmd.Flags().StringVar(&o.mode, "upload-mode", defaultMode,
+		"How to send local files: auto (data URI up to 1 MB, fal storage above), fal, datauri, s3")
+	cmd.Flags().StringVar(&o.bucket, "s3-bucket", "",
+		"Bucket for --upload-mode s3 (overrides config); implies s3 in auto mode")
+	cmd.Flags().StringVar(&o.publicURL, "s3-public-url", "",
+		"Public base URL of the bucket (e.g. https://pub.example.com; overrides config)")
+	cmd.Flags().BoolVar(&o.noCache, "no-upload-cache", false,
+		"Always upload files, even if the same content was uploaded recently")
+
+	// Flags of the former r2 CLI uploader.
+	cmd.Flags().StringVar(&o.bucket, "r2-bucket", "", "R2 bucket")
+	cmd.Flags().StringVar(&o.publicURL, "r2-domain", "", "R2 public domain")
+	_ = cmd.Flags().MarkDeprecated("r2-bucket", "use --s3-bucket")
+	_ = cmd.Flags().MarkDeprecated("r2-domain", "use --s3-public-url")
+}
+
+// uploadFile turns one local file into a URL the model can fetch. Uploads
+// are cached by content hash (see uploadcache) unless o.noCache is set.
+func uploadFile(cmd *cobra.Command, path string, o uploadOptions) (string, error) {
+	st, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !st.Mode().IsRegular() {
+		return "", fmt.Errorf("%s is not a regular file", path)
+	}
+
+	mode := o.mode
+	if mode == uploadModeAuto {
+		switch {
+		case o.bucket != "":
+			mode = uploadModeS3
+		case st.Size() > dataURIMaxBytes:
+			mode = uploadModeFal
+		default:
+			// A small file already on fal storage is cheaper to send by URL.
+			mode = uploadModeDataURI
+			if !o.noCache {
+				if hash, err := uploadcache.HashFile(path); err == nil {
+					if _, ok := uploadcache.Lookup(hash, uploadModeFal); ok {
+						mode = uploadModeFal
+					}
+				}
+			}
+		}
+	}
+
+	var backend, key string
+	var ttl time.Duration
+	var upload func() (string, error)
+	var s *storage.Client
+	switch mode {
+	case uploadModeDataURI:
+		fmt.Fprintf(cmd.ErrOrStderr(), "  encoding %s as base64 (%s)\n", filepath.Base(path), mimeFromPath(path))
+		return fileToDataURI(path)
+	case uploadModeFal:
+		backend, ttl = uploadModeFal, uploadcache.FalTTL
+		upload = func() (string, error) { return uploadToFal(cmd, path, st.Size()) }
+	case uploadModeS3, uploadModeR2:
+		if s, err = resolveStorage(o.bucket, o.publicURL); err != nil {
+			return "", err
+		}
+		backend, ttl = uploadModeS3+":"+s.Bucket(), uploadcache.StorageTTL
+	default:
+		return "", fmt.Errorf("unknown upload mode %q (use auto, fal, datauri or s3)", o.mode)
+	}
+
+	hash, err := uploadcache.HashFile(path)
+	if err != nil {
+		return "", err
+	}
+	if s != nil {
+		// Keys derive from the content hash, so files with the same name
+		// never overwrite each other.
+		key = s.Prefix() + hash + strings.ToLower(filepath.Ext(path))
+		upload = func() (string, error) { return uploadToStorage(cmd, s, path, key) }
+	}
+	if !o.noCache {
+		if e, ok := uploadcache.Lookup(hash, backend); ok {
+			fmt.Fprintf(cmd.ErrOrStderr(), "  %s already uploaded (cached until %s)\n  → %s\n",
+				filepath.Base(path), e.ExpiresAt.Local().Format("2006-01-02 15:04"), e.URL)
+			return e.URL, nil
+		}
+	}
+
+	u, err := upload()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().UTC()
+	err = uploadcache.Record(uploadcache.Entry{
+		Hash:       hash,
+		Backend:    backend,
+		URL:        u,
+		Key:        key,
+		Name:       filepath.Base(path),
+		Size:       st.Size(),
+		UploadedAt: now,
+		ExpiresAt:  now.Add(ttl),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not update upload cache: %s\n", err)
+	}
+	return u, nil
+}
+
+// uploadToStorage puts path in the bucket under key.
+func uploadToStorage(cmd *cobra.Command, s *storage.Client, path, key string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "  uploading %s → %s/%s (%s)\n", filepath.Base(path), s.Bucket(), key, formatBytes(int64(len(data))))
+	if err := s.Put(cmd.Context(), key, data, mimeFromPath(path)); err != nil {
+		return "", err
+	}
+	u := s.URL(key)
+	fmt.Fprintf(cmd.ErrOrStderr(), "  → %s\n", u)
+	return u, nil
+}
+
+// uploadToFal uploads path to fal storage, showing progress on a terminal
+// for large files.
+func uploadToFal(cmd *cobra.Command, path string, size int64) (string, error) {
+	name := filepath.Base(path)
+	stderr := cmd.ErrOrStderr()
+
+	var progress api.UploadProgress
+	if size >= progressMinBytes && isatty.IsTerminal(os.Stderr.Fd()) {
+		last := -1
+		progress = func(sent, total int64) {
+			pct := int(sent * 100 / total)
+			if pct == last {
+				return
+			}
+			last = pct
+			fmt.Fprintf(stderr, "\r  uploading %s to fal storage... %3d%% of %s", name, pct, formatBytes(size))
+		}
+	} else {
+		fmt.Fprintf(stderr, "  uploading %s to fal storage (%s)...\n", name, formatBytes(size))
+	}
+
+	u, err := client.UploadFile(cmd.Context(), path, progress)
+	if progress != nil {
+		fmt.Fprintln(stderr)
+	}
+	if err != nil {
+		return "", err
+	}
+	fmt.Fprintf(stderr, "  → %s\n", u)
+	return u, nil
+}
+
+// formatBytes renders a size like "840 KB" or "12.3 MB".
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// uploadLocalRefs replaces local file references in payload, in place,
+// with uploaded URLs. A reference is either a string "@file:<path>"
+// anywhere in the payload, or a bare path to an existing local file in a
+// field whose name ends in "_url" or "_urls". Each distinct file is
+// uploaded once.
+func uploadLocalRefs(cmd *cobra.Command, payload map[string]any, o uploadOptions) error {
+	r := &refUploader{cmd: cmd, opts: o, done: map[string]string{}}
+	return r.walkMap(payload)
+}
+
+type refUploader struct {
+	cmd  *cobra.Command
+	opts uploadOptions
+	done map[string]string // local path → uploaded URL
+}
+
+func (r *refUploader) walkMap(m map[string]any) error {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		urlField := strings.HasSuffix(k, "_url") || strings.HasSuffix(k, "_urls")
+		v, err := r.walk(m[k], urlField)
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		m[k] = v
+	}
+	return nil
+}
+
+// walk returns v with file references replaced. urlField reports whether
+// v sits in a *_url / *_urls field, where bare paths count as references.
+func (r *refUploader) walk(v any, urlField bool) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		return t, r.walkMap(t)
+	case []any:
+		for i := range t {
+			item, err := r.walk(t[i], urlField)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = item
+		}
+		return t, nil
+	case []string: // payloads built by the shortcuts
+		for i := range t {
+			item, err := r.walk(t[i], urlField)
+			if err != nil {
+				return nil, err
+			}
+			t[i] = item.(string)
+		}
+		return t, nil
+	case string:
+		path, ok := localRef(t, urlField)
+		if !ok {
+			return t, nil
+		}
+		if u, seen := r.done[path]; seen {
+			return u, nil
+		}
+		u, err := uploadFile(r.cmd, path, r.opts)
+		if err != nil {
+			return nil, fmt.Errorf("uploading %s: %w", path, err)
+		}
+		r.done[path] = u
+		return u, nil
+	}
+	return v, nil
+}
+
+// localRef reports whether s refers to a local file, and its path.
+func localRef(s string, urlField bool) (string, bool) {
+	if strings.HasPrefix(s, fileRefPrefix) {
+		return strings.TrimPrefix(s, fileRefPrefix), true
+	}
+	if !urlField || s == "" || strings.Contains(s, "://") || strings.HasPrefix(s, "data:") {
+		return "", false
+	}
+	if st, err := os.Stat(s); err == nil && st.Mode().IsRegular() {
+		return s, true
+	}
+	return "", false
+}
@@ -2,7 +2,9 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -14,26 +16,90 @@ import (
 	"time"
 )
 
+// Default base URLs, used unless overridden with WithEndpoints.
 const (
-	runBase   = "https://fal.run"
-	queueBase = "https://queue.fal.run"
-	apiBase   = "https://api.fal.ai/v1"
+	DefaultRunURL   = "https://fal.run"
+	DefaultQueueURL = "https://queue.fal.run"
+	DefaultAPIURL   = "https://api.fal.ai/v1"
 )
 
+// Endpoints holds the base URLs the client talks to. Overriding them lets
+// the CLI go through a proxy, a regional gateway, or a local fake server.
+type Endpoints struct {
+	Run   string // synchronous runs
+	Queue string // queue submit/status/result/cancel
+	API   string // platform API: models, pricing, file uploads
+}
+
+// DefaultEndpoints returns the public fal.ai endpoints.
+func DefaultEndpoints() Endpoints {
+	return Endpoints{
+		Run:   DefaultRunURL,
+		Queue: DefaultQueueURL,
+		API:   DefaultAPIURL,
+	}
+}
+
+// CancelTimeout bounds how long a best-effort cancel issued after the
+// caller's context is done may take.
+const CancelTimeout = 10 * time.Second
+
 // Client is an authenticated fal.ai API client.
 type Client struct {
 	apiKey     string
 	httpClient *http.Client
+	endpoints  Endpoints
+	retry      RetryPolicy
+	logger     io.Writer
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithRetryPolicy sets the policy used to retry 429 and 5xx responses.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) { c.retry = p }
+}
+
+// WithEndpoints overrides the base URLs. Empty fields keep their defaults.
+func WithEndpoints(e Endpoints) Option {
+	return func(c *Client) {
+		if e.Run != "" {
+			c.endpoints.Run = strings.TrimRight(e.Run, "/")
+		}
+		if e.Queue != "" {
+			c.endpoints.Queue = strings.TrimRight(e.Queue, "/")
+		}
+		if e.API != "" {
+			c.endpoints.API = strings.TrimRight(e.API, "/")
+		}
+	}
+}
+
+// WithLogger makes the client report retries to w (verbose mode).
+func WithLogger(w io.Writer) Option {
+	return func(c *Client) { c.logger = w }
 }
 
 // NewClient creates a new authenticated Client.
-func NewClient(apiKey string) *Client {
-	return &Client{
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
 		apiKey: apiKey,
 		httpClient: &http.Client{
 			Timeout: 120 * time.Second,
 		},
+		endpoints: DefaultEndpoints(),
+		retry:     DefaultRetryPolicy(),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Endpoints returns the base URLs in effect.
+func (c *Client) Endpoints() Endpoints {
+	return c.endpoints
 }
 
 // authHeader returns the Authorization header value.
@@ -41,52 +107,107 @@ func (c *Client) authHeader() string {
 	return "Key " + c.apiKey
 }
 
+// logf writes a diagnostic line when a logger is configured.
+func (c *Client) logf(format string, args ...any) {
+	if c.logger != nil {
+		fmt.Fprintf(c.logger, format+"\n", args...)
+	}
+}
+
 // doRequest executes an HTTP request and returns the body bytes.
+// Responses with status 429 or 5xx (and transport errors) are retried
+// according to the client's RetryPolicy when the method allows it.
 func (c *Client) doRequest(req *http.Request) ([]byte, error) {
+	body, _, err := c.doRequestHeader(req)
+	return body, err
+}
+
+// doRequestHeader is doRequest that also returns the response headers.
+func (c *Client) doRequestHeader(req *http.Request) ([]byte, http.Header, error) {
 	req.Header.Set("Authorization", c.authHeader())
 	req.Header.Set("Accept", "application/json")
 
+	// A request body can only be replayed if it can be recreated.
+	canRetry := c.retry.allows(req.Method) && (req.Body == nil || req.GetBody != nil)
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			b, err := req.GetBody()
+			if err != nil {
+				return nil, nil, err
+			}
+			req.Body = b
+		}
+
+		body, statusCode, header, err := c.doOnce(req)
+		if err == nil || !canRetry || attempt >= c.retry.MaxRetries ||
+			req.Context().Err() != nil || !retryableStatus(statusCode) {
+			return body, header, err
+		}
+
+		delay := c.retry.delay(attempt, header)
+		c.logf("retry %d/%d: %s %s: %s (waiting %s)",
+			attempt+1, c.retry.MaxRetries, req.Method, req.URL.Path, summarizeError(err), delay.Round(time.Millisecond))
+		if err := sleepCtx(req.Context(), delay); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// doOnce performs a single attempt of req. statusCode is 0 when no response
+// was received.
+func (c *Client) doOnce(req *http.Request) (body []byte, statusCode int, header http.Header, err error) {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, 0, nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err = io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("reading response: %w", err)
+		return nil, 0, resp.Header, fmt.Errorf("reading response: %w", err)
 	}
 
 	if resp.StatusCode >= 400 {
 		// Try to parse fal error format
 		var falErr FalError
 		if json.Unmarshal(body, &falErr) == nil && falErr.Detail != "" {
-			return nil, &falErr
+			if falErr.Status == 0 {
+				falErr.Status = resp.StatusCode
+			}
+			falErr.Body = body
+			return nil, resp.StatusCode, resp.Header, &falErr
 		}
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, resp.StatusCode, resp.Header, &HTTPError{StatusCode: resp.StatusCode, Body: body}
 	}
 
-	return body, nil
+	return body, resp.StatusCode, resp.Header, nil
 }
 
 // postJSON makes a POST request with a JSON body to the given full URL.
-func (c *Client) postJSON(fullURL string, payload any) ([]byte, error) {
+func (c *Client) postJSON(ctx context.Context, fullURL string, payload any) ([]byte, error) {
+	body, _, err := c.postJSONHeader(ctx, fullURL, payload)
+	return body, err
+}
+
+// postJSONHeader is postJSON that also returns the response headers.
+func (c *Client) postJSONHeader(ctx context.Context, fullURL string, payload any) ([]byte, http.Header, error) {
 	data, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("encoding request: %w", err)
+		return nil, nil, fmt.Errorf("encoding request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, fullURL, bytes.NewReader(data))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullURL, bytes.NewReader(data))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	return c.doRequest(req)
+	return c.doRequestHeader(req)
 }
 
 // get makes a GET request to the given full URL with optional query params.
-func (c *Client) get(fullURL string, params url.Values) ([]byte, error) {
+func (c *Client) get(ctx context.Context, fullURL string, params url.Values) ([]byte, error) {
 	if len(params) > 0 {
 		u, err := url.Parse(fullURL)
 		if err != nil {
@@ -102,7 +223,7 @@ func (c *Client) get(fullURL string, params url.Values) ([]byte, error) {
 		fullURL = u.String()
 	}
 
-	req, err := http.NewRequest(http.MethodGet, fullURL, nil)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +232,8 @@ func (c *Client) get(fullURL string, params url.Values) ([]byte, error) {
 }
 
 // put makes a PUT request to the given full URL (used for cancel).
-func (c *Client) put(fullURL string) ([]byte, error) {
-	req, err := http.NewRequest(http.MethodPut, fullURL, nil)
+func (c *Client) put(ctx context.Context, fullURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fullURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -122,11 +243,17 @@ func (c *Client) put(fullURL string) ([]byte, error) {
 
 // ---- Sync run ----
 
-// RunSync submits a synchronous request to a model and returns the raw response.
+// RunSync submits a synchronous request to a model and returns the raw
+// response along with the request ID fal assigned to it (empty if the
+// response did not carry one).
 // modelID is e.g. "fal-ai/nano-banana-pro" or "fal-ai/nano-banana-pro/edit".
-func (c *Client) RunSync(modelID string, payload any) ([]byte, error) {
-	endpoint := runBase + "/" + strings.TrimPrefix(modelID, "/")
-	return c.postJSON(endpoint, payload)
+func (c *Client) RunSync(ctx context.Context, modelID string, payload any) ([]byte, string, error) {
+	endpoint := c.endpoints.Run + "/" + strings.TrimPrefix(modelID, "/")
+	body, header, err := c.postJSONHeader(ctx, endpoint, payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, header.Get("X-Fal-Request-Id"), nil
 }
 
 // baseModelID returns the owner/alias portion of a model ID, stripping any
@@ -143,9 +270,9 @@ func baseModelID(modelID string) string {
 // ---- Queue ----
 
 // QueueSubmit submits a request to the queue and returns queue metadata.
-func (c *Client) QueueSubmit(modelID string, payload any) (*QueueSubmitResponse, error) {
-	endpoint := queueBase + "/" + strings.TrimPrefix(modelID, "/")
-	body, err := c.postJSON(endpoint, payload)
+func (c *Client) QueueSubmit(ctx context.Context, modelID string, payload any) (*QueueSubmitResponse, error) {
+	endpoint := c.endpoints.Queue + "/" + strings.TrimPrefix(modelID, "/")
+	body, err := c.postJSON(ctx, endpoint, payload)
 	if err != nil {
 		return nil, err
 	}
@@ -159,16 +286,16 @@ func (c *Client) QueueSubmit(modelID string, payload any) (*QueueSubmitResponse,
 
 // QueueStatus polls the status of a queued request.
 // modelID is required to build the status URL.
-func (c *Client) QueueStatus(modelID, requestID string, withLogs bool) (*QueueStatus, error) {
+func (c *Client) QueueStatus(ctx context.Context, modelID, requestID string, withLogs bool) (*QueueStatus, error) {
 	endpoint := fmt.Sprintf("%s/%s/requests/%s/status",
-		queueBase, baseModelID(modelID), requestID)
+		c.endpoints.Queue, baseModelID(modelID), requestID)
 
 	params := url.Values{}
 	if withLogs {
 		params.Set("logs", "1")
 	}
 
-	body, err := c.get(endpoint, params)
+	body, err := c.get(ctx, endpoint, params)
 	if err != nil {
 		return nil, err
 	}
@@ -181,36 +308,39 @@ func (c *Client) QueueStatus(modelID, requestID string, withLogs bool) (*QueueSt
 }
 
 // QueueResult retrieves the completed result for a request.
-func (c *Client) QueueResult(modelID, requestID string) ([]byte, error) {
+func (c *Client) QueueResult(ctx context.Context, modelID, requestID string) ([]byte, error) {
 	endpoint := fmt.Sprintf("%s/%s/requests/%s",
-		queueBase, baseModelID(modelID), requestID)
-	return c.get(endpoint, nil)
+		c.endpoints.Queue, baseModelID(modelID), requestID)
+	return c.get(ctx, endpoint, nil)
 }
 
 // QueueCancel cancels a queued request.
-func (c *Client) QueueCancel(modelID, requestID string) error {
+func (c *Client) QueueCancel(ctx context.Context, modelID, requestID string) error {
 	endpoint := fmt.Sprintf("%s/%s/requests/%s/cancel",
-		queueBase, baseModelID(modelID), requestID)
-	_, err := c.put(endpoint)
+		c.endpoints.Queue, baseModelID(modelID), requestID)
+	_, err := c.put(ctx, endpoint)
 	return err
 }
 
 // ---- Queue + poll helper ----
 
-// RunQueued submits to queue, polls until done, then returns the result.
-// progress is called on each status poll if non-nil.
-func (c *Client) RunQueued(modelID string, payload any, progress func(status *QueueStatus)) ([]byte, error) {
-	sub, err := c.QueueSubmit(modelID, payload)
-	if err != nil {
-		return nil, err
-	}
-
+// QueueWait polls a queued request until it reaches a terminal status, then
+// returns the result. progress is called on each status poll if non-nil, and
+// logs are requested only when withLogs is set.
+//
+// A request that ends in any status other than a clean COMPLETED yields a
+// *QueueError carrying the model's error payload and logs. If ctx is done
+// first, QueueWait returns ctx.Err() and leaves the request running; callers
+// decide whether to cancel it.
+func (c *Client) QueueWait(ctx context.Context, modelID, requestID string, withLogs bool, progress func(status *QueueStatus)) ([]byte, error) {
 	// Poll with backoff: 1s, 2s, 4s, 8s, then every 10s
 	delay := time.Second
 	maxDelay := 10 * time.Second
 
 	for {
-		time.Sleep(delay)
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
 		if delay < maxDelay {
 			delay *= 2
 			if delay > maxDelay {
@@ -218,8 +348,11 @@ func (c *Client) RunQueued(modelID string, payload any, progress func(status *Qu
 			}
 		}
 
-		status, err := c.QueueStatus(modelID, sub.RequestID, progress != nil)
+		status, err := c.QueueStatus(ctx, modelID, requestID, withLogs)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			return nil, err
 		}
 
@@ -227,18 +360,115 @@ func (c *Client) RunQueued(modelID string, payload any, progress func(status *Qu
 			progress(status)
 		}
 
-		if status.Status == "COMPLETED" {
-			return c.QueueResult(modelID, sub.RequestID)
+		if !status.IsTerminal() {
+			continue
+		}
+		if status.Succeeded() {
+			body, err := c.QueueResult(ctx, modelID, requestID)
+			var falErr *FalError
+			if errors.As(err, &falErr) {
+				return nil, c.queueFailure(ctx, modelID, requestID, status, err)
+			}
+			return body, err
 		}
+		_, resultErr := c.QueueResult(ctx, modelID, requestID)
+		return nil, c.queueFailure(ctx, modelID, requestID, status, resultErr)
+	}
+}
+
+// queueFailure builds a QueueError for a request that ended without a usable
+// result. resultErr is the error returned when fetching its result, which
+// carries the model's error payload.
+func (c *Client) queueFailure(ctx context.Context, modelID, requestID string, status *QueueStatus, resultErr error) *QueueError {
+	qe := &QueueError{
+		RequestID: requestID,
+		Status:    status.Status,
+		ErrorType: status.ErrorType,
+		Message:   status.Error,
+		Logs:      status.Logs,
+	}
+
+	var falErr *FalError
+	var httpErr *HTTPError
+	switch {
+	case errors.As(resultErr, &falErr):
+		if qe.Message == "" {
+			qe.Message = falErr.Error()
+		}
+		qe.Payload = falErr.Body
+	case errors.As(resultErr, &httpErr):
+		qe.Payload = httpErr.Body
+	}
+	if qe.Status == StatusCompleted {
+		qe.Status = "FAILED"
+	}
+
+	// Fetch logs once so the failure can be diagnosed even when polling
+	// did not request them.
+	if len(qe.Logs) == 0 {
+		if st, err := c.QueueStatus(ctx, modelID, requestID, true); err == nil {
+			qe.Logs = st.Logs
+		}
+	}
+	return qe
+}
+
+// RunQueued submits to queue, polls until done, then returns the result.
+// progress is called on each status poll if non-nil.
+//
+// If ctx is cancelled while the request is still running, RunQueued asks the
+// queue to cancel it, so the job does not keep running (and billing) after
+// the caller has given up on it. The error is then ctx.Err(), joined with
+// the cancel error if the cancel failed and the request may still be
+// running.
+func (c *Client) RunQueued(ctx context.Context, modelID string, payload any, progress func(status *QueueStatus)) ([]byte, error) {
+	sub, err := c.QueueSubmit(ctx, modelID, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.QueueWait(ctx, modelID, sub.RequestID, progress != nil, progress)
+	if err != nil && ctx.Err() != nil {
+		if cancelErr := c.cancelDetached(modelID, sub.RequestID); cancelErr != nil {
+			return nil, errors.Join(ctx.Err(), fmt.Errorf("cancelling request %s: %w", sub.RequestID, cancelErr))
+		}
+		return nil, ctx.Err()
+	}
+	return body, err
+}
+
+// cancelDetached cancels a queued request using a fresh, short-lived context.
+// It is used after the caller's context is already done, when a request made
+// with that context would fail immediately.
+func (c *Client) cancelDetached(modelID, requestID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), CancelTimeout)
+	defer cancel()
+	return c.QueueCancel(ctx, modelID, requestID)
+}
+
+// sleepCtx waits for d or until ctx is done, whichever comes first.
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
 	}
 }
 
 // ---- Platform API: File upload ----
 
+// UploadProgress is called while an upload is sent, with the number of
+// bytes written so far and the total request size.
+type UploadProgress func(sent, total int64)
+
 // UploadFile uploads a local file to fal.ai storage and returns its CDN URL.
 // Uses the serverless files API: POST /v1/serverless/files/file/local/{filename}
 // with the file content as a multipart form field "file_upload".
-func (c *Client) UploadFile(localPath string) (string, error) {
+// progress may be nil.
+func (c *Client) UploadFile(ctx context.Context, localPath string, progress UploadProgress) (string, error) {
 	f, err := os.Open(localPath)
 	if err != nil {
 		return "", fmt.Errorf("opening %s: %w", localPath, err)
@@ -258,12 +488,20 @@ func (c *Client) UploadFile(localPath string) (string, error) {
 	w.Close()
 
 	targetPath := url.PathEscape(filepath.Base(localPath))
-	endpoint := fmt.Sprintf("%s/serverless/files/file/local/%s", apiBase, targetPath)
+	endpoint := fmt.Sprintf("%s/serverless/files/file/local/%s", c.endpoints.API, targetPath)
 
-	req, err := http.NewRequest(http.MethodPost, endpoint, &buf)
+	// The body is wrapped to report progress, so the length and GetBody
+	// (used for retries) have to be set by hand.
+	data := buf.Bytes()
+	newBody := func() io.Reader {
+		return &progressReader{r: bytes.NewReader(data), total: int64(len(data)), fn: progress}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, newBody())
 	if err != nil {
 		return "", err
 	}
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(newBody()), nil }
 	req.Header.Set("Content-Type", w.FormDataContentType())
 
 	body, err := c.doRequest(req)
@@ -281,10 +519,26 @@ func (c *Client) UploadFile(localPath string) (string, error) {
 	return resp.URL, nil
 }
 
+// progressReader reports how much of a request body has been read.
+type progressReader struct {
+	r           io.Reader
+	sent, total int64
+	fn          UploadProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.sent += int64(n)
+	if n > 0 && p.fn != nil {
+		p.fn(p.sent, p.total)
+	}
+	return n, err
+}
+
 // ---- Platform API: Models ----
 
 // ListModels fetches models from the catalog with optional filters.
-func (c *Client) ListModels(q, category, cursor string, limit int) (*ModelsResponse, error) {
+func (c *Client) ListModels(ctx context.Context, q, category, cursor string, limit int) (*ModelsResponse, error) {
 	params := url.Values{}
 	if q != "" {
 		params.Set("q", q)
@@ -299,7 +553,7 @@ func (c *Client) ListModels(q, category, cursor string, limit int) (*ModelsRespo
 		params.Set("limit", fmt.Sprintf("%d", limit))
 	}
 
-	body, err := c.get(apiBase+"/models", params)
+	body, err := c.get(ctx, c.endpoints.API+"/models", params)
 	if err != nil {
 		return nil, err
 	}
@@ -311,14 +565,60 @@ func (c *Client) ListModels(q, category, cursor string, limit int) (*ModelsRespo
 	return &resp, nil
 }
 
-// GetModelPricing fetches pricing for one or more model endpoint IDs.
-func (c *Client) GetModelPricing(endpointIDs []string) (*PricingResponse, error) {
+// GetModel fetches a single model from the catalog. With withOpenAPI, the
+// response also carries the endpoint's OpenAPI document.
+func (c *Client) GetModel(ctx context.Context, endpointID string, withOpenAPI bool) (*Model, error) {
+	params := url.Values{}
+	params.Set("endpoint_id", endpointID)
+	if withOpenAPI {
+		params.Set("expand", "openapi-3.0")
+	}
+
+	body, err := c.get(ctx, c.endpoints.API+"/models", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ModelsResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("parsing models: %w", err)
+	}
+	for _, m := range resp.Models {
+		if m.EndpointID == endpointID {
+			return &m, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrModelNotFound, endpointID)
+}
+
+// ModelPages returns a Pager over the catalog listing, starting at cursor
+// ("" for the first page) with pageSize models per request (0 for the API
+// default).
+func (c *Client) ModelPages(ctx context.Context, q, category, cursor string, pageSize int) *Pager[Model] {
+	return newPager(ctx, cursor, func(ctx context.Context, cursor string) ([]Model, string, bool, error) {
+		resp, err := c.ListModels(ctx, q, category, cursor, pageSize)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return resp.Models, resp.NextCursor, resp.HasMore, nil
+	})
+}
+
+// ListPricing fetches one page of prices. With no endpoint IDs, the API
+// lists the prices of every model.
+func (c *Client) ListPricing(ctx context.Context, endpointIDs []string, cursor string, limit int) (*PricingResponse, error) {
 	params := url.Values{}
 	for _, id := range endpointIDs {
 		params.Add("endpoint_id", id)
 	}
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	}
+	if limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", limit))
+	}
 
-	body, err := c.get(apiBase+"/models/pricing", params)
+	body, err := c.get(ctx, c.endpoints.API+"/models/pricing", params)
 	if err != nil {
 		return nil, err
 	}
@@ -329,3 +629,25 @@ func (c *Client) GetModelPricing(endpointIDs []string) (*PricingResponse, error)
 	}
 	return &resp, nil
 }
+
+// PricingPages returns a Pager over the prices of endpointIDs (or of every
+// model when empty), like ModelPages.
+func (c *Client) PricingPages(ctx context.Context, endpointIDs []string, cursor string, pageSize int) *Pager[ModelPrice] {
+	return newPager(ctx, cursor, func(ctx context.Context, cursor string) ([]ModelPrice, string, bool, error) {
+		resp, err := c.ListPricing(ctx, endpointIDs, cursor, pageSize)
+		if err != nil {
+			return nil, "", false, err
+		}
+		return resp.Prices, resp.NextCursor, resp.HasMore, nil
+	})
+}
+
+// GetModelPricing fetches pricing for one or more model endpoint IDs,
+// following the cursor until every page has been read.
+func (c *Client) GetModelPricing(ctx context.Context, endpointIDs []string) (*PricingResponse, error) {
+	prices, err := c.PricingPages(ctx, endpointIDs, "", 0).All()
+	if err != nil {
+		return nil, err
+	}
+	return &PricingResponse{Prices: prices}, nil
+}
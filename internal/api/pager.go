@@ -0,0 +1,75 @@
+package api
+
+import "context"
+
+// Pager walks a cursor-paginated listing one page at a time:
+//
+//	p := client.ModelPages(ctx, "flux", "", "", 100)
+//	for p.Next() {
+//		for _, m := range p.Page() { ... }
+//	}
+//	if err := p.Err(); err != nil { ... }
+//
+// Next stops after the last page, on the first error, or when the API
+// hands back a cursor it already returned (so a misbehaving server cannot
+// loop forever).
+type Pager[T any] struct {
+	ctx    context.Context
+	fetch  func(ctx context.Context, cursor string) (items []T, next string, more bool, err error)
+	cursor string
+	seen   map[string]bool
+	page   []T
+	done   bool
+	err    error
+}
+
+func newPager[T any](ctx context.Context, cursor string, fetch func(context.Context, string) ([]T, string, bool, error)) *Pager[T] {
+	return &Pager[T]{ctx: ctx, fetch: fetch, cursor: cursor, seen: map[string]bool{}}
+}
+
+// Next fetches the next page and reports whether there is one.
+func (p *Pager[T]) Next() bool {
+	if p.done {
+		return false
+	}
+	p.seen[p.cursor] = true
+	items, next, more, err := p.fetch(p.ctx, p.cursor)
+	if err != nil {
+		p.err = err
+		p.done = true
+		p.page = nil
+		return false
+	}
+	p.page = items
+	if !more || next == "" || p.seen[next] {
+		next = ""
+		p.done = true
+	}
+	p.cursor = next
+	return true
+}
+
+// Page returns the items of the current page.
+func (p *Pager[T]) Page() []T {
+	return p.page
+}
+
+// Cursor returns the cursor of the page after the current one, or "" once
+// the listing is exhausted. It can be passed back to resume later.
+func (p *Pager[T]) Cursor() string {
+	return p.cursor
+}
+
+// Err returns the error that stopped Next, if any.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All drains the pager and returns every remaining item.
+func (p *Pager[T]) All() ([]T, error) {
+	var all []T
+	for p.Next() {
+		all = append(all, p.Page()...)
+	}
+	return all, p.Err()
+}
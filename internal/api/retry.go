@@ -0,0 +1,109 @@
+package api
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy controls how the client retries rate-limited (429) and server
+// error (5xx) responses.
+//
+// GET and PUT requests (status, result, cancel, catalog) are idempotent and
+// retried by default. POST requests submit new work, so a retried POST may
+// run a model twice; they are only retried when RetryPOST is set.
+type RetryPolicy struct {
+	MaxRetries int           // retries after the first attempt; 0 disables retrying
+	BaseDelay  time.Duration // delay before the first retry
+	MaxDelay   time.Duration // upper bound for the exponential backoff
+	RetryPOST  bool          // also retry POST requests (queue/sync submits, uploads)
+}
+
+// maxRetryAfter caps a server-provided Retry-After delay.
+const maxRetryAfter = 5 * time.Minute
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 3,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// allows reports whether requests with the given method may be retried.
+func (p RetryPolicy) allows(method string) bool {
+	if p.MaxRetries <= 0 {
+		return false
+	}
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		return p.RetryPOST
+	}
+	return false
+}
+
+// delay returns how long to wait before retry number attempt+1. A
+// Retry-After header takes precedence; otherwise the delay is a capped
+// exponential backoff with jitter (between half and all of the backoff).
+func (p RetryPolicy) delay(attempt int, header http.Header) time.Duration {
+	if d, ok := parseRetryAfter(header); ok {
+		return d
+	}
+
+	backoff := p.BaseDelay << attempt
+	if backoff <= 0 || backoff > p.MaxDelay {
+		backoff = p.MaxDelay
+	}
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as
+// an HTTP date.
+func parseRetryAfter(header http.Header) (time.Duration, bool) {
+	v := strings.TrimSpace(header.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = time.Until(t)
+	} else {
+		return 0, false
+	}
+
+	if d < 0 {
+		d = 0
+	}
+	if d > maxRetryAfter {
+		d = maxRetryAfter
+	}
+	return d, true
+}
+
+// retryableStatus reports whether a response status is worth retrying.
+// statusCode 0 means the request failed before a response was received
+// (connection reset, DNS failure, ...).
+func retryableStatus(statusCode int) bool {
+	return statusCode == 0 || statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// summarizeError shortens an error for a one-line retry report.
+func summarizeError(err error) string {
+	msg := strings.Join(strings.Fields(err.Error()), " ")
+	if len(msg) > 120 {
+		msg = msg[:119] + "…"
+	}
+	return msg
+}
@@ -1,6 +1,13 @@
 package api
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
 
 // ---- Queue / Run types ----
 
@@ -12,11 +19,32 @@ type QueueSubmitResponse struct {
 	CancelURL   string `json:"cancel_url"`
 }
 
+// Queue status values reported by the queue API.
+const (
+	StatusInQueue    = "IN_QUEUE"
+	StatusInProgress = "IN_PROGRESS"
+	StatusCompleted  = "COMPLETED"
+)
+
 // QueueStatus represents the current status of a queued request.
 type QueueStatus struct {
-	Status        string     `json:"status"` // IN_QUEUE, IN_PROGRESS, COMPLETED
+	Status        string     `json:"status"` // IN_QUEUE, IN_PROGRESS, COMPLETED, ...
 	QueuePosition *int       `json:"queue_position,omitempty"`
 	Logs          []LogEntry `json:"logs,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	ErrorType     string     `json:"error_type,omitempty"`
+}
+
+// IsTerminal reports whether the request has stopped running. Every status
+// other than IN_QUEUE and IN_PROGRESS is terminal, so failures, cancellations
+// and status values added to the API later all end polling.
+func (s *QueueStatus) IsTerminal() bool {
+	return s.Status != StatusInQueue && s.Status != StatusInProgress
+}
+
+// Succeeded reports whether the request completed without an error.
+func (s *QueueStatus) Succeeded() bool {
+	return s.Status == StatusCompleted && s.Error == ""
 }
 
 // LogEntry is a single log line from a running model.
@@ -45,6 +73,9 @@ type ModelMetadata struct {
 type Model struct {
 	EndpointID string        `json:"endpoint_id"`
 	Metadata   ModelMetadata `json:"metadata"`
+	// OpenAPI is the endpoint's OpenAPI document, only present when
+	// requested with expand=openapi-3.0.
+	OpenAPI json.RawMessage `json:"openapi,omitempty"`
 }
 
 // ModelsResponse is the paginated list of models.
@@ -54,6 +85,9 @@ type ModelsResponse struct {
 	HasMore    bool    `json:"has_more"`
 }
 
+// ErrModelNotFound is returned by GetModel for an unknown endpoint ID.
+var ErrModelNotFound = errors.New("model not found in the fal catalog")
+
 // ---- Pricing types ----
 
 // ModelPrice is the price entry for a single model.
@@ -82,6 +116,49 @@ type ImageFile struct {
 	Height      int    `json:"height"`
 }
 
+// FindFiles returns every file object found anywhere in a model result: any
+// JSON object whose "url" is an http(s) or data URL. This covers images,
+// video, audio and other media without knowing the model's output schema.
+// Object keys are visited in sorted order so the result is stable.
+func FindFiles(result []byte) []ImageFile {
+	var v any
+	if err := json.Unmarshal(result, &v); err != nil {
+		return nil
+	}
+	var files []ImageFile
+	collectFiles(v, &files)
+	return files
+}
+
+func collectFiles(v any, files *[]ImageFile) {
+	switch t := v.(type) {
+	case map[string]any:
+		if u, ok := t["url"].(string); ok && isFileURL(u) {
+			var f ImageFile
+			if data, err := json.Marshal(t); err == nil && json.Unmarshal(data, &f) == nil {
+				*files = append(*files, f)
+				return
+			}
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			collectFiles(t[k], files)
+		}
+	case []any:
+		for _, item := range t {
+			collectFiles(item, files)
+		}
+	}
+}
+
+func isFileURL(u string) bool {
+	return strings.HasPrefix(u, "https://") || strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "data:")
+}
+
 // GenerateResponse is the output from image generation models.
 type GenerateResponse struct {
 	Images      []ImageFile     `json:"images"`
@@ -100,12 +177,142 @@ type FileUploadResponse struct {
 	FileSize    int64  `json:"file_size"`
 }
 
+// ---- Queue errors ----
+
+// Sentinel errors wrapped by QueueError, for use with errors.Is.
+var (
+	ErrRequestFailed    = errors.New("request failed")
+	ErrRequestCancelled = errors.New("request cancelled")
+)
+
+// QueueError describes a queued request that reached a terminal status
+// without producing a result.
+type QueueError struct {
+	RequestID string
+	Status    string
+	ErrorType string
+	Message   string          // error message from the status or result payload
+	Payload   json.RawMessage // raw error payload returned by the model, if any
+	Logs      []LogEntry
+}
+
+// Cancelled reports whether the request ended because it was cancelled.
+func (e *QueueError) Cancelled() bool {
+	switch strings.ToUpper(e.Status) {
+	case "CANCELLED", "CANCELED":
+		return true
+	}
+	return false
+}
+
+func (e *QueueError) Error() string {
+	msg := e.Message
+	if msg == "" && len(e.Payload) > 0 {
+		msg = string(e.Payload)
+	}
+	if msg == "" {
+		msg = "no result available"
+	}
+	return fmt.Sprintf("request %s ended with status %s: %s", e.RequestID, e.Status, msg)
+}
+
+// Unwrap returns ErrRequestCancelled or ErrRequestFailed.
+func (e *QueueError) Unwrap() error {
+	if e.Cancelled() {
+		return ErrRequestCancelled
+	}
+	return ErrRequestFailed
+}
+
+// ---- HTTP errors ----
+
+// HTTPError is returned for error responses that are not in the fal error format.
+type HTTPError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, string(e.Body))
+}
+
 // ---- fal API error ----
 
-// FalError represents an error returned by the fal.ai API.
+// FalError represents an error returned by the fal.ai API. The "detail"
+// field is either a message or, for input validation failures (HTTP 422),
+// a list of per-field errors, which are kept in Errors.
 type FalError struct {
-	Detail string `json:"detail"`
-	Status int    `json:"status"`
+	Detail string            `json:"detail"`
+	Status int               `json:"status"`
+	Errors []ValidationError `json:"errors,omitempty"`
+
+	// Body is the response body the error was parsed from, verbatim.
+	Body json.RawMessage `json:"-"`
+}
+
+// ValidationError is one entry of a 422 "detail" array.
+type ValidationError struct {
+	Loc  []any  `json:"loc"`
+	Msg  string `json:"msg"`
+	Type string `json:"type,omitempty"`
+}
+
+// Field returns the dotted path of the invalid field, without the leading
+// "body" location (e.g. "image_size.width").
+func (v ValidationError) Field() string {
+	var parts []string
+	for i, l := range v.Loc {
+		if i == 0 && l == "body" {
+			continue
+		}
+		switch t := l.(type) {
+		case string:
+			parts = append(parts, t)
+		case float64:
+			parts = append(parts, strconv.Itoa(int(t)))
+		default:
+			parts = append(parts, fmt.Sprint(t))
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+func (v ValidationError) String() string {
+	if f := v.Field(); f != "" {
+		return f + ": " + v.Msg
+	}
+	return v.Msg
+}
+
+func (e *FalError) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Detail json.RawMessage   `json:"detail"`
+		Status int               `json:"status"`
+		Errors []ValidationError `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	e.Status = raw.Status
+	e.Errors = raw.Errors
+	e.Detail = ""
+	if len(raw.Detail) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Detail, &e.Detail); err == nil {
+		return nil
+	}
+	if err := json.Unmarshal(raw.Detail, &e.Errors); err != nil {
+		// Unknown shape: keep it verbatim rather than dropping it.
+		e.Detail = string(raw.Detail)
+		return nil
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, v := range e.Errors {
+		msgs[i] = v.String()
+	}
+	e.Detail = "invalid input: " + strings.Join(msgs, "; ")
+	return nil
 }
 
 func (e *FalError) Error() string {
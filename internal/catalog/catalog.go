@@ -0,0 +1,166 @@
+// Package catalog keeps a local copy of the fal model catalog and its
+// pricing, written by "fal models sync", so models can be listed and
+// searched without network access.
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/config"
+)
+
+// ErrNotSynced is returned by Load when the catalog has never been synced.
+var ErrNotSynced = errors.New(`no local model catalog; run "fal models sync" first`)
+
+// Catalog is the synced model catalog.
+type Catalog struct {
+	SyncedAt time.Time        `json:"synced_at"`
+	Models   []api.Model      `json:"models"`
+	Prices   []api.ModelPrice `json:"prices"`
+}
+
+// Price returns the price of endpointID, if known.
+func (c *Catalog) Price(endpointID string) (api.ModelPrice, bool) {
+	for _, p := range c.Prices {
+		if p.EndpointID == endpointID {
+			return p, true
+		}
+	}
+	return api.ModelPrice{}, false
+}
+
+// Path returns the catalog file path.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "catalog.json"), nil
+}
+
+// Load reads the local catalog, or returns ErrNotSynced.
+func Load() (*Catalog, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotSynced
+		}
+		return nil, err
+	}
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Store writes c as the local catalog, atomically.
+func Store(c *Catalog) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFileAtomic(path, data, 0600)
+}
+
+// Kinds of Change.
+const (
+	Added         = "added"
+	Removed       = "removed"
+	StatusChanged = "status"
+	PriceChanged  = "price"
+)
+
+// Change is one difference between two syncs of the catalog. A model whose
+// status and price both changed yields two changes.
+type Change struct {
+	EndpointID string          `json:"endpoint_id"`
+	Kind       string          `json:"kind"`
+	Name       string          `json:"name,omitempty"`
+	OldStatus  string          `json:"old_status,omitempty"`
+	NewStatus  string          `json:"new_status,omitempty"`
+	OldPrice   *api.ModelPrice `json:"old_price,omitempty"`
+	NewPrice   *api.ModelPrice `json:"new_price,omitempty"`
+}
+
+// Diff lists the models added, removed, or with a new status or price in
+// cur compared to prev, sorted by endpoint ID. A price that was not known
+// in prev (or is no longer known in cur) is not reported as a change.
+func Diff(prev, cur *Catalog) []Change {
+	before := make(map[string]api.Model, len(prev.Models))
+	for _, m := range prev.Models {
+		before[m.EndpointID] = m
+	}
+	after := make(map[string]api.Model, len(cur.Models))
+	for _, m := range cur.Models {
+		after[m.EndpointID] = m
+	}
+	oldPrices, newPrices := priceMap(prev.Prices), priceMap(cur.Prices)
+
+	var changes []Change
+	for id, m := range after {
+		old, ok := before[id]
+		if !ok {
+			c := Change{EndpointID: id, Kind: Added, Name: m.Metadata.DisplayName, NewStatus: m.Metadata.Status}
+			if p, ok := newPrices[id]; ok {
+				c.NewPrice = &p
+			}
+			changes = append(changes, c)
+			continue
+		}
+		if old.Metadata.Status != m.Metadata.Status {
+			changes = append(changes, Change{
+				EndpointID: id, Kind: StatusChanged, Name: m.Metadata.DisplayName,
+				OldStatus: old.Metadata.Status, NewStatus: m.Metadata.Status,
+			})
+		}
+		op, okOld := oldPrices[id]
+		np, okNew := newPrices[id]
+		if okOld && okNew && (op.UnitPrice != np.UnitPrice || op.Unit != np.Unit || op.Currency != np.Currency) {
+			changes = append(changes, Change{
+				EndpointID: id, Kind: PriceChanged, Name: m.Metadata.DisplayName,
+				OldPrice: &op, NewPrice: &np,
+			})
+		}
+	}
+	for id, m := range before {
+		if _, ok := after[id]; !ok {
+			changes = append(changes, Change{EndpointID: id, Kind: Removed, Name: m.Metadata.DisplayName, OldStatus: m.Metadata.Status})
+		}
+	}
+
+	sort.Slice(changes, func(a, b int) bool {
+		if changes[a].EndpointID != changes[b].EndpointID {
+			return changes[a].EndpointID < changes[b].EndpointID
+		}
+		return changes[a].Kind < changes[b].Kind
+	})
+	return changes
+}
+
+func priceMap(prices []api.ModelPrice) map[string]api.ModelPrice {
+	m := make(map[string]api.ModelPrice, len(prices))
+	for _, p := range prices {
+		m[p.EndpointID] = p
+	}
+	return m
+}
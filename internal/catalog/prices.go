@@ -0,0 +1,100 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/config"
+	"github.com/the20100/fal-cli/internal/filelock"
+)
+
+// PriceMaxAge is how long a price looked up for a single model is reused
+// before it is fetched again.
+const PriceMaxAge = 24 * time.Hour
+
+// cachedPrice is one entry of the price cache.
+type cachedPrice struct {
+	api.ModelPrice
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// priceMu serializes price cache updates within the process; StorePrice
+// also takes a lock file against other processes.
+var priceMu sync.Mutex
+
+func pricesPath() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "prices.json"), nil
+}
+
+// CachedPrice returns the most recent known price of endpointID, from the
+// price cache or the synced catalog, and when it was fetched.
+func CachedPrice(endpointID string) (api.ModelPrice, time.Time, bool) {
+	priceMu.Lock()
+	all, _ := loadPrices()
+	priceMu.Unlock()
+
+	best, ok := all[endpointID]
+	if c, err := Load(); err == nil && (!ok || c.SyncedAt.After(best.FetchedAt)) {
+		if p, found := c.Price(endpointID); found {
+			best, ok = cachedPrice{ModelPrice: p, FetchedAt: c.SyncedAt}, true
+		}
+	}
+	return best.ModelPrice, best.FetchedAt, ok
+}
+
+// StorePrice adds p to the price cache.
+func StorePrice(p api.ModelPrice) error {
+	priceMu.Lock()
+	defer priceMu.Unlock()
+
+	path, err := pricesPath()
+	if err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+	defer unlock()
+
+	all, err := loadPrices()
+	if err != nil {
+		all = map[string]cachedPrice{}
+	}
+	all[p.EndpointID] = cachedPrice{ModelPrice: p, FetchedAt: time.Now().UTC()}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(path, data, 0600)
+}
+
+func loadPrices() (map[string]cachedPrice, error) {
+	path, err := pricesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return map[string]cachedPrice{}, nil
+		}
+		return nil, err
+	}
+	all := map[string]cachedPrice{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,145 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/the20100/fal-cli/internal/api"
+	"github.com/the20100/fal-cli/internal/textdist"
+)
+
+// Field weights: a hit in the endpoint ID or name counts more than one in
+// the tags, which counts more than one in the description.
+const (
+	weightID          = 4
+	weightName        = 4
+	weightTag         = 2
+	weightDescription = 1
+)
+
+// Search returns the models matching query, best matches first. Every word
+// of the query must match the endpoint ID, display name, tags or
+// description of a model, either as a substring, as a near miss of one of
+// their words (one or two typos), or, for the ID and name, as an
+// abbreviation whose letters appear in order ("fdev" for "flux/dev"). An
+// empty query matches every model, in catalog order.
+func Search(models []api.Model, query string) []api.Model {
+	terms := words(query)
+	if len(terms) == 0 {
+		return models
+	}
+
+	type hit struct {
+		model api.Model
+		score int
+	}
+	var hits []hit
+	for _, m := range models {
+		total := 0
+		for _, t := range terms {
+			s := scoreTerm(m, t)
+			if s == 0 {
+				total = 0
+				break
+			}
+			total += s
+		}
+		if total > 0 {
+			hits = append(hits, hit{m, total})
+		}
+	}
+
+	sort.SliceStable(hits, func(a, b int) bool {
+		if hits[a].score != hits[b].score {
+			return hits[a].score > hits[b].score
+		}
+		return hits[a].model.EndpointID < hits[b].model.EndpointID
+	})
+	out := make([]api.Model, len(hits))
+	for i, h := range hits {
+		out[i] = h.model
+	}
+	return out
+}
+
+// scoreTerm scores the best match of one query word against a model, or
+// returns 0 when it does not match at all.
+func scoreTerm(m api.Model, term string) int {
+	best := 0
+	try := func(text string, weight int, abbrev bool) {
+		if s := matchText(strings.ToLower(text), term, abbrev) * weight; s > best {
+			best = s
+		}
+	}
+	try(m.EndpointID, weightID, true)
+	try(m.Metadata.DisplayName, weightName, true)
+	for _, tag := range m.Metadata.Tags {
+		try(tag, weightTag, false)
+	}
+	try(m.Metadata.Description, weightDescription, false)
+	return best
+}
+
+// matchText scores term against text: 4 for a whole word, 3 for a word
+// prefix, 2 for any other substring, 1 for a near miss of a word or (with
+// abbrev) an in-order subsequence, 0 otherwise.
+func matchText(text, term string, abbrev bool) int {
+	if text == "" {
+		return 0
+	}
+	ws := words(text)
+	for _, w := range ws {
+		if w == term {
+			return 4
+		}
+	}
+	for _, w := range ws {
+		if strings.HasPrefix(w, term) {
+			return 3
+		}
+	}
+	if strings.Contains(text, term) {
+		return 2
+	}
+	if len(term) >= 5 {
+		maxDist := 1
+		if len(term) >= 9 {
+			maxDist = 2
+		}
+		for _, w := range ws {
+			if abs(len(w)-len(term)) <= maxDist && textdist.Levenshtein(w, term) <= maxDist {
+				return 1
+			}
+		}
+	}
+	if abbrev && len(term) >= 3 && isSubsequence(term, text) {
+		return 1
+	}
+	return 0
+}
+
+// words splits s into lower-case runs of letters and digits.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isSubsequence(sub, s string) bool {
+	want := []rune(sub)
+	i := 0
+	for _, r := range s {
+		if i < len(want) && want[i] == r {
+			i++
+		}
+	}
+	return i == len(want)
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
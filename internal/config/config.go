@@ -10,19 +10,67 @@ import (
 // Config holds the persisted user configuration.
 type Config struct {
 	APIKey string `json:"api_key"`
+
+	// Retries overrides the number of retries for 429/5xx responses
+	// (nil = built-in default, 0 = disabled).
+	Retries *int `json:"retries,omitempty"`
+	// RetryPOST also retries POST submits, which may run a model twice.
+	RetryPOST bool `json:"retry_post,omitempty"`
+
+	// Base URL overrides (proxy, regional gateway, local fake server).
+	// FAL_RUN_URL, FAL_QUEUE_URL and FAL_API_URL take precedence.
+	RunURL   string `json:"run_url,omitempty"`
+	QueueURL string `json:"queue_url,omitempty"`
+	APIURL   string `json:"api_url,omitempty"`
+
+	// Storage is the S3-compatible bucket used by --upload-mode s3.
+	Storage *Storage `json:"storage,omitempty"`
+
+	// Budget caps the estimated spend of submitted requests.
+	Budget *Budget `json:"budget,omitempty"`
 }
 
-// configPath returns the path to the config file.
-// Uses os.UserConfigDir() for cross-platform support:
-//   - macOS:   ~/Library/Application Support/fal/config.json
-//   - Linux:   ~/.config/fal/config.json
-//   - Windows: %AppData%\fal\config.json
-func configPath() (string, error) {
+// Storage configures an S3-compatible bucket (R2, S3, MinIO, ...).
+// FAL_S3_* env vars (and AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+// AWS_REGION) take precedence.
+type Storage struct {
+	Endpoint        string `json:"endpoint,omitempty"`
+	Region          string `json:"region,omitempty"`
+	Bucket          string `json:"bucket,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	PublicURL       string `json:"public_url,omitempty"`
+	Prefix          string `json:"prefix,omitempty"`
+}
+
+// Budget holds spending caps, in the currency of model prices (USD).
+// A zero cap means no limit.
+type Budget struct {
+	Daily      float64 `json:"daily,omitempty"`
+	Monthly    float64 `json:"monthly,omitempty"`
+	PerCommand float64 `json:"per_command,omitempty"` // max estimated cost of one request
+}
+
+// Dir returns the fal config directory, which also holds local state such
+// as the job ledger. Uses os.UserConfigDir() for cross-platform support:
+//   - macOS:   ~/Library/Application Support/fal
+//   - Linux:   ~/.config/fal
+//   - Windows: %AppData%\fal
+func Dir() (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(dir, "fal", "config.json"), nil
+	return filepath.Join(dir, "fal"), nil
+}
+
+// configPath returns the path to the config file inside Dir().
+func configPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
 }
 
 // Load reads the config file. Returns an empty Config (not an error) if file doesn't exist.
@@ -84,3 +132,25 @@ func Path() string {
 	p, _ := configPath()
 	return p
 }
+
+// WriteFileAtomic replaces path with data through a temp file in the same
+// directory, so a concurrent reader never sees a partial file and a crash
+// never leaves one behind.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,271 @@
+package download
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/api"
+)
+
+// DefaultTemplate is the file naming template used when none is given.
+const DefaultTemplate = "{model}_{seed}_{index}.{ext}"
+
+// DefaultConcurrency is the number of files fetched in parallel.
+const DefaultConcurrency = 4
+
+// Options controls where and how outputs are saved.
+type Options struct {
+	Dir       string // destination directory (created if missing)
+	Template  string // file name template, see Render
+	Overwrite bool   // replace existing files instead of picking a new name
+
+	// Template variables shared by every file of one result.
+	Model     string
+	Seed      string
+	RequestID string
+}
+
+// Result is the outcome of downloading one file.
+type Result struct {
+	URL  string `json:"url"`
+	Path string `json:"path,omitempty"`
+	Err  error  `json:"-"`
+}
+
+var httpClient = &http.Client{Timeout: 10 * time.Minute}
+
+// All downloads every file in parallel into opts.Dir and returns one Result
+// per file, in the same order. Files are never sent the fal API key: output
+// URLs are public CDN links.
+//
+// Unless opts.Overwrite is set, a name that already exists on disk (or is
+// taken by another file of the same batch, or by another download running
+// at the same time) gets a numeric suffix instead of being overwritten.
+func All(ctx context.Context, files []api.ImageFile, opts Options) []Result {
+	results := make([]Result, len(files))
+	if len(files) == 0 {
+		return results
+	}
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+
+	// Claim every destination up front, in order, so the first file of a
+	// result gets the plain name when several render the same.
+	for i, f := range files {
+		name := Render(opts.Template, templateVars(opts, f, i))
+		dest := filepath.Join(opts.Dir, name)
+		results[i] = Result{URL: f.URL, Path: dest}
+		// The template may name subdirectories, e.g. "{model}/{index}.{ext}".
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if !opts.Overwrite {
+			results[i].Path, results[i].Err = claim(dest)
+		}
+	}
+
+	sem := make(chan struct{}, DefaultConcurrency)
+	var wg sync.WaitGroup
+	for i := range files {
+		if results[i].Err != nil {
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if err := fetch(ctx, files[i].URL, results[i].Path); err != nil {
+				results[i].Err = err
+				if !opts.Overwrite {
+					os.Remove(results[i].Path) // the empty file claim left
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+// Render fills a naming template. A "/" in the template's own text makes a
+// subdirectory; variable values never do. Supported variables:
+//
+//	{model}       model ID with "/" replaced by "-"
+//	{seed}        seed reported by the model ("noseed" if absent)
+//	{index}       1-based position of the file in the result
+//	{ext}         file extension without the dot
+//	{name}        original file name without extension
+//	{request_id}  fal request ID
+func Render(template string, vars map[string]string) string {
+	out := template
+	for k, v := range vars {
+		out = strings.ReplaceAll(out, "{"+k+"}", sanitize(v))
+	}
+	return out
+}
+
+func templateVars(opts Options, f api.ImageFile, index int) map[string]string {
+	ext := Extension(f)
+	name := strings.TrimSuffix(f.FileName, path.Ext(f.FileName))
+	if name == "" {
+		name = "output"
+	}
+	seed := opts.Seed
+	if seed == "" {
+		seed = "noseed"
+	}
+	return map[string]string{
+		"model":      strings.ReplaceAll(strings.Trim(opts.Model, "/"), "/", "-"),
+		"seed":       seed,
+		"index":      strconv.Itoa(index + 1),
+		"ext":        ext,
+		"name":       name,
+		"request_id": opts.RequestID,
+	}
+}
+
+// commonExtensions maps content types to their usual extension, since
+// mime.ExtensionsByType may list rarer ones (".jfif") first.
+var commonExtensions = map[string]string{
+	"image/png":         "png",
+	"image/jpeg":        "jpg",
+	"image/jpg":         "jpg",
+	"image/webp":        "webp",
+	"image/gif":         "gif",
+	"video/mp4":         "mp4",
+	"video/webm":        "webm",
+	"audio/mpeg":        "mp3",
+	"audio/wav":         "wav",
+	"audio/x-wav":       "wav",
+	"model/gltf-binary": "glb",
+}
+
+// Extension picks a file extension (without the dot) from the file name,
+// then the content type, then the URL path.
+func Extension(f api.ImageFile) string {
+	if ext := strings.TrimPrefix(path.Ext(f.FileName), "."); ext != "" {
+		return strings.ToLower(ext)
+	}
+	ct, _, _ := mime.ParseMediaType(f.ContentType)
+	if ext, ok := commonExtensions[ct]; ok {
+		return ext
+	}
+	if exts, _ := mime.ExtensionsByType(ct); len(exts) > 0 {
+		return strings.TrimPrefix(exts[0], ".")
+	}
+	if u, err := url.Parse(f.URL); err == nil && u.Scheme != "data" {
+		if ext := strings.TrimPrefix(path.Ext(u.Path), "."); ext != "" {
+			return strings.ToLower(ext)
+		}
+	}
+	return "bin"
+}
+
+// sanitize makes a template value safe to use in a file name.
+func sanitize(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '-'
+		}
+		return r
+	}, v)
+}
+
+// claim creates an empty file at dest, or at dest with a "_N" suffix when
+// dest exists, and returns its path. Creating it exclusively reserves the
+// name: downloads running in parallel, in this process or another, never
+// pick the same one.
+func claim(dest string) (string, error) {
+	ext := filepath.Ext(dest)
+	base := strings.TrimSuffix(dest, ext)
+	candidate := dest
+	for n := 1; ; n++ {
+		f, err := os.OpenFile(candidate, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			return candidate, f.Close()
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		candidate = fmt.Sprintf("%s_%d%s", base, n, ext)
+	}
+}
+
+// fetch downloads rawURL to dest through a temp file in the same directory,
+// so an interrupted download never leaves a truncated file behind. dest is
+// replaced if it exists.
+func fetch(ctx context.Context, rawURL, dest string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), ".fal-download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := copyTo(ctx, rawURL, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
+
+// copyTo writes the content behind rawURL (http(s) or data URL) to w.
+func copyTo(ctx context.Context, rawURL string, w io.Writer) error {
+	if strings.HasPrefix(rawURL, "data:") {
+		header, data, ok := strings.Cut(rawURL, ",")
+		if !ok {
+			return fmt.Errorf("malformed data URL")
+		}
+		var decoded []byte
+		var err error
+		if strings.HasSuffix(header, ";base64") {
+			decoded, err = base64.StdEncoding.DecodeString(data)
+		} else {
+			var s string
+			s, err = url.PathUnescape(data)
+			decoded = []byte(s)
+		}
+		if err != nil {
+			return fmt.Errorf("decoding data URL: %w", err)
+		}
+		_, err = w.Write(decoded)
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("downloading: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("downloading: HTTP %d", resp.StatusCode)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
@@ -0,0 +1,112 @@
+package download
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/api"
+)
+
+func TestAllConcurrentSameNames(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond) // keep both calls in flight together
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	// Outputs of an earlier run must survive too.
+	if err := os.WriteFile(filepath.Join(dir, "m_noseed_1.png"), []byte("earlier"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two batch lines of a model that reports no seed render the same names.
+	var wg sync.WaitGroup
+	results := make([][]Result, 2)
+	for line := range results {
+		wg.Add(1)
+		go func(line int) {
+			defer wg.Done()
+			files := []api.ImageFile{
+				{URL: srv.URL + "/line" + string(rune('a'+line)) + "-1", ContentType: "image/png"},
+				{URL: srv.URL + "/line" + string(rune('a'+line)) + "-2", ContentType: "image/png"},
+			}
+			results[line] = All(context.Background(), files, Options{Dir: dir, Model: "m"})
+		}(line)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, rs := range results {
+		for _, r := range rs {
+			if r.Err != nil {
+				t.Fatalf("%s: %v", r.URL, r.Err)
+			}
+			if seen[r.Path] {
+				t.Errorf("%s is reported for two files", r.Path)
+			}
+			seen[r.Path] = true
+			data, err := os.ReadFile(r.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := r.URL[len(srv.URL):]; string(data) != want {
+				t.Errorf("%s holds %q, want %q", r.Path, data, want)
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	if len(names) != 5 {
+		t.Errorf("directory holds %v, want the earlier file and 4 downloads", names)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "m_noseed_1.png")); string(data) != "earlier" {
+		t.Errorf("existing file was overwritten with %q", data)
+	}
+}
+
+func TestAllFailedDownloadReleasesName(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	rs := All(context.Background(), []api.ImageFile{{URL: srv.URL + "/x.png"}}, Options{Dir: dir, Model: "m"})
+	if rs[0].Err == nil {
+		t.Fatal("expected an error")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("failed download left %d file(s) behind", len(entries))
+	}
+}
+
+func TestAllTemplateSubdirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := []api.ImageFile{{URL: "data:text/plain,hello", ContentType: "image/png"}}
+	rs := All(context.Background(), files, Options{Dir: dir, Template: "{model}/{index}.{ext}", Model: "fal-ai/flux/dev"})
+	if rs[0].Err != nil {
+		t.Fatal(rs[0].Err)
+	}
+	if want := filepath.Join(dir, "fal-ai-flux-dev", "1.png"); rs[0].Path != want {
+		t.Errorf("saved to %s, want %s", rs[0].Path, want)
+	}
+	if data, _ := os.ReadFile(rs[0].Path); string(data) != "hello" {
+		t.Errorf("file holds %q, want %q", data, "hello")
+	}
+}
@@ -0,0 +1,255 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/config"
+	"github.com/the20100/fal-cli/internal/filelock"
+)
+
+// Status values recorded for jobs in addition to the queue's own
+// (IN_QUEUE, IN_PROGRESS, COMPLETED).
+const (
+	StatusFailed    = "FAILED"
+	StatusCancelled = "CANCELLED"
+)
+
+// maxJobs bounds the ledger size; the oldest entries are dropped first.
+const maxJobs = 1000
+
+// maxInlineValue is the longest payload string kept verbatim. Longer data
+// URIs (base64-encoded --file inputs) are replaced with a short placeholder.
+const maxInlineValue = 512
+
+// ErrNotFound is returned when no job matches a request ID or prefix.
+var ErrNotFound = errors.New("no job found")
+
+// Job is one model request submitted through the CLI.
+type Job struct {
+	RequestID   string         `json:"request_id"`
+	ModelID     string         `json:"model_id"`
+	Mode        string         `json:"mode"` // "queue" or "sync"
+	Status      string         `json:"status"`
+	Error       string         `json:"error,omitempty"`
+	Payload     map[string]any `json:"payload,omitempty"`
+	Outputs     []string       `json:"outputs,omitempty"`
+	SubmittedAt time.Time      `json:"submitted_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// mu serializes ledger updates within the process (batch runs record
+// jobs from several goroutines); a lock file does the same across
+// processes.
+var mu sync.Mutex
+
+// Path returns the ledger file path.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "jobs.json"), nil
+}
+
+// List returns all recorded jobs, most recently submitted first.
+func List() ([]Job, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load()
+}
+
+// Find returns the job whose request ID equals idOrPrefix or, failing that,
+// the single job whose request ID starts with it.
+func Find(idOrPrefix string) (*Job, error) {
+	all, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Job
+	for _, j := range all {
+		if j.RequestID == idOrPrefix {
+			return &j, nil
+		}
+		if strings.HasPrefix(j.RequestID, idOrPrefix) {
+			matches = append(matches, j)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w for %q in the local ledger", ErrNotFound, idOrPrefix)
+	case 1:
+		return &matches[0], nil
+	}
+	ids := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ids = append(ids, m.RequestID)
+	}
+	return nil, fmt.Errorf("request ID prefix %q is ambiguous: %s", idOrPrefix, strings.Join(ids, ", "))
+}
+
+// Record inserts j into the ledger, replacing any job with the same
+// request ID.
+func Record(j Job) error {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockLedger()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := load()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if j.SubmittedAt.IsZero() {
+		j.SubmittedAt = now
+	}
+	j.UpdatedAt = now
+	j.Payload = compactPayload(j.Payload)
+
+	for i := range all {
+		if all[i].RequestID == j.RequestID {
+			all[i] = j
+			return save(all)
+		}
+	}
+	all = append([]Job{j}, all...)
+	return save(all)
+}
+
+// Update applies fn to the job with the given request ID and saves the
+// ledger. It returns ErrNotFound if the job was never recorded.
+func Update(requestID string, fn func(j *Job)) error {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockLedger()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := load()
+	if err != nil {
+		return err
+	}
+	for i := range all {
+		if all[i].RequestID == requestID {
+			fn(&all[i])
+			all[i].UpdatedAt = time.Now().UTC()
+			return save(all)
+		}
+	}
+	return ErrNotFound
+}
+
+// lockLedger takes the lock file guarding load+save against other
+// processes.
+func lockLedger() (func(), error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return unlock, nil
+}
+
+// load reads the ledger. A missing file is an empty ledger.
+func load() ([]Job, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var all []Job
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	sort.SliceStable(all, func(a, b int) bool {
+		return all[a].SubmittedAt.After(all[b].SubmittedAt)
+	})
+	return all, nil
+}
+
+// save writes the ledger atomically, keeping the newest maxJobs entries.
+func save(all []Job) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if len(all) > maxJobs {
+		all = all[:maxJobs]
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFileAtomic(path, data, 0600)
+}
+
+// compactPayload returns a copy of payload with long data URIs replaced by
+// a placeholder, so base64-encoded inputs do not bloat the ledger.
+func compactPayload(payload map[string]any) map[string]any {
+	if payload == nil {
+		return nil
+	}
+	out, _ := compactValue(payload).(map[string]any)
+	return out
+}
+
+func compactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, item := range t {
+			out[k] = compactValue(item)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = compactValue(item)
+		}
+		return out
+	case []string:
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = compactValue(item)
+		}
+		return out
+	case string:
+		if len(t) > maxInlineValue && strings.HasPrefix(t, "data:") {
+			header, _, _ := strings.Cut(t, ",")
+			return fmt.Sprintf("%s,<%d bytes omitted>", header, len(t))
+		}
+		return t
+	}
+	return v
+}
@@ -0,0 +1,162 @@
+package provenance
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// XMP is stored in an APP1 segment that starts with this identifier.
+var xmpHeader = []byte("http://ns.adobe.com/xap/1.0/\x00")
+
+// Exif is stored in an APP1 segment that starts with this identifier.
+var exifHeader = []byte("Exif\x00\x00")
+
+// Namespace of the fal provenance properties inside the XMP packet.
+const xmpNamespace = "https://fal.ai/ns/provenance/1.0/"
+
+// maxSegment is the largest payload a JPEG marker segment can hold.
+const maxSegment = 0xFFFF - 2
+
+// embedJPEG inserts an XMP APP1 segment after SOI and the APP0 (JFIF) and
+// Exif APP1 segments that lead the file, replacing an XMP packet already
+// present. Exif must directly follow SOI or APP0, so XMP never goes first.
+func embedJPEG(data []byte, info Info) ([]byte, error) {
+	segments, rest, err := jpegSegments(data)
+	if err != nil {
+		return nil, err
+	}
+
+	packet := append(append([]byte{}, xmpHeader...), xmpPacket(info)...)
+	if len(packet) > maxSegment {
+		return nil, fmt.Errorf("provenance too large for a JPEG XMP segment (%d bytes)", len(packet))
+	}
+
+	var buf bytes.Buffer
+	buf.Write(jpegSOI)
+	inserted := false
+	for _, s := range segments {
+		if s.marker == 0xE1 && bytes.HasPrefix(s.data, xmpHeader) {
+			continue // replaced below
+		}
+		leading := s.marker == 0xE0 || (s.marker == 0xE1 && bytes.HasPrefix(s.data, exifHeader))
+		if !inserted && !leading {
+			writeJPEGSegment(&buf, 0xE1, packet)
+			inserted = true
+		}
+		writeJPEGSegment(&buf, s.marker, s.data)
+	}
+	if !inserted {
+		writeJPEGSegment(&buf, 0xE1, packet)
+	}
+	buf.Write(rest)
+	return buf.Bytes(), nil
+}
+
+// readJPEG parses the fal properties from the XMP packet, if any.
+func readJPEG(data []byte) (*Info, error) {
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range segments {
+		if s.marker == 0xE1 && bytes.HasPrefix(s.data, xmpHeader) {
+			return parseXMP(s.data[len(xmpHeader):])
+		}
+	}
+	return nil, nil
+}
+
+type jpegSegment struct {
+	marker byte
+	data   []byte
+}
+
+// jpegSegments returns the marker segments between SOI and the start of
+// scan, and the remaining bytes (SOS onwards) untouched.
+func jpegSegments(data []byte) ([]jpegSegment, []byte, error) {
+	var segments []jpegSegment
+	pos := len(jpegSOI)
+	for {
+		if pos+4 > len(data) || data[pos] != 0xFF {
+			return nil, nil, errors.New("invalid JPEG: bad segment marker")
+		}
+		marker := data[pos+1]
+		if marker == 0xDA || marker == 0xD9 { // SOS or EOI: image data follows
+			return segments, data[pos:], nil
+		}
+		n := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if n < 2 || pos+2+n > len(data) {
+			return nil, nil, errors.New("invalid JPEG: truncated segment")
+		}
+		segments = append(segments, jpegSegment{marker: marker, data: data[pos+4 : pos+2+n]})
+		pos += 2 + n
+	}
+}
+
+func writeJPEGSegment(buf *bytes.Buffer, marker byte, data []byte) {
+	buf.Write([]byte{0xFF, marker})
+	var length [2]byte
+	binary.BigEndian.PutUint16(length[:], uint16(len(data)+2))
+	buf.Write(length[:])
+	buf.Write(data)
+}
+
+// xmpPacket renders info as an XMP packet. The prompt is also stored as
+// dc:description so ordinary image viewers show it.
+func xmpPacket(info Info) []byte {
+	var b strings.Builder
+	b.WriteString(`<?xpacket begin="` + "\uFEFF" + `" id="W5M0MpCehiHzreSzNTczkc9d"?>` + "\n")
+	b.WriteString(`<x:xmpmeta xmlns:x="adobe:ns:meta/">` + "\n")
+	b.WriteString(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#">` + "\n")
+	b.WriteString(`<rdf:Description rdf:about=""` +
+		` xmlns:fal="` + xmpNamespace + `"` +
+		` xmlns:xmp="http://ns.adobe.com/xap/1.0/"` +
+		` xmlns:dc="http://purl.org/dc/elements/1.1/"`)
+	for _, f := range info.fields() {
+		b.WriteString("\n  fal:" + f[0] + `="` + xmlEscape(f[1]) + `"`)
+	}
+	b.WriteString("\n  xmp:CreatorTool=\"" + xmlEscape(info.Tool) + `">`)
+	if info.Prompt != "" {
+		b.WriteString("\n  <dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">" +
+			xmlEscape(info.Prompt) + "</rdf:li></rdf:Alt></dc:description>")
+	}
+	b.WriteString("\n</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n")
+	b.WriteString(`<?xpacket end="w"?>`)
+	return []byte(b.String())
+}
+
+// parseXMP reads the fal:* attributes of the rdf:Description element.
+func parseXMP(packet []byte) (*Info, error) {
+	dec := xml.NewDecoder(bytes.NewReader(packet))
+	var info *Info
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "Description" {
+			continue
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Space != xmpNamespace {
+				continue
+			}
+			if info == nil {
+				info = &Info{}
+			}
+			info.set(attr.Name.Local, attr.Value)
+		}
+	}
+	return info, nil
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,175 @@
+package provenance
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"strings"
+	"unicode"
+)
+
+// PNG keywords are "fal:<field>". ASCII values go in tEXt chunks (Latin-1);
+// anything else, typically the prompt, goes in an uncompressed iTXt chunk,
+// which is UTF-8.
+const pngKeywordPrefix = "fal:"
+
+// embedPNG inserts provenance chunks right after IHDR, replacing any fal
+// chunks already present.
+func embedPNG(data []byte, info Info) ([]byte, error) {
+	chunks, err := pngChunks(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 || chunks[0].typ != "IHDR" {
+		return nil, errors.New("invalid PNG: IHDR chunk missing")
+	}
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	for i, c := range chunks {
+		if isFalTextChunk(c) {
+			continue
+		}
+		buf.Write(data[c.start:c.end])
+		if i == 0 {
+			for _, f := range info.fields() {
+				writePNGText(&buf, pngKeywordPrefix+f[0], f[1])
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// readPNG collects fal tEXt/iTXt chunks. It returns nil when there are none.
+func readPNG(data []byte) (*Info, error) {
+	chunks, err := pngChunks(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var info *Info
+	for _, c := range chunks {
+		key, value, ok := parsePNGText(c)
+		if !ok || !strings.HasPrefix(key, pngKeywordPrefix) {
+			continue
+		}
+		if info == nil {
+			info = &Info{}
+		}
+		info.set(strings.TrimPrefix(key, pngKeywordPrefix), value)
+	}
+	return info, nil
+}
+
+type pngChunk struct {
+	typ        string
+	data       []byte
+	start, end int // span of the whole chunk (length..CRC) in the file
+}
+
+// pngChunks splits a PNG file into its chunks, up to IEND.
+func pngChunks(data []byte) ([]pngChunk, error) {
+	var chunks []pngChunk
+	pos := len(pngSignature)
+	for pos < len(data) {
+		if pos+8 > len(data) {
+			return nil, errors.New("invalid PNG: truncated chunk header")
+		}
+		n := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + n
+		if n < 0 || end > len(data) {
+			return nil, errors.New("invalid PNG: truncated chunk")
+		}
+		chunks = append(chunks, pngChunk{
+			typ:   string(data[pos+4 : pos+8]),
+			data:  data[pos+8 : pos+8+n],
+			start: pos,
+			end:   end,
+		})
+		pos = end
+		if chunks[len(chunks)-1].typ == "IEND" {
+			return chunks, nil
+		}
+	}
+	return nil, errors.New("invalid PNG: IEND chunk missing")
+}
+
+func isFalTextChunk(c pngChunk) bool {
+	key, _, ok := parsePNGText(c)
+	return ok && strings.HasPrefix(key, pngKeywordPrefix)
+}
+
+// parsePNGText decodes a tEXt or uncompressed iTXt chunk.
+func parsePNGText(c pngChunk) (key, value string, ok bool) {
+	switch c.typ {
+	case "tEXt":
+		k, v, found := bytes.Cut(c.data, []byte{0})
+		if !found {
+			return "", "", false
+		}
+		return string(k), latin1ToString(v), true
+	case "iTXt":
+		// keyword \0 compression-flag compression-method language \0 translated-keyword \0 text
+		k, rest, found := bytes.Cut(c.data, []byte{0})
+		if !found || len(rest) < 2 || rest[0] != 0 {
+			return "", "", false // compressed iTXt is never written by us
+		}
+		rest = rest[2:]
+		if _, rest, found = bytes.Cut(rest, []byte{0}); !found {
+			return "", "", false
+		}
+		if _, rest, found = bytes.Cut(rest, []byte{0}); !found {
+			return "", "", false
+		}
+		return string(k), string(rest), true
+	}
+	return "", "", false
+}
+
+// writePNGText writes value as a tEXt chunk when it is printable ASCII and
+// as an iTXt chunk otherwise.
+func writePNGText(buf *bytes.Buffer, key, value string) {
+	var body bytes.Buffer
+	body.WriteString(key)
+	body.WriteByte(0)
+	typ := "tEXt"
+	if !isASCII(value) {
+		typ = "iTXt"
+		body.Write([]byte{0, 0}) // uncompressed
+		body.WriteByte(0)        // no language tag
+		body.WriteByte(0)        // no translated keyword
+	}
+	body.WriteString(value)
+
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(body.Len()))
+	buf.Write(length[:])
+
+	crc := crc32.NewIEEE()
+	crc.Write([]byte(typ))
+	crc.Write(body.Bytes())
+	buf.WriteString(typ)
+	buf.Write(body.Bytes())
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc.Sum32())
+	buf.Write(sum[:])
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII || (r < 0x20 && r != '\n') {
+			return false
+		}
+	}
+	return true
+}
+
+func latin1ToString(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
@@ -0,0 +1,137 @@
+package provenance
+
+import (
+	"bytes"
+	"errors"
+	"os"
+
+	"github.com/the20100/fal-cli/internal/config"
+)
+
+// Tool is recorded as the software that produced a file.
+const Tool = "fal-cli"
+
+// ErrUnsupported is returned for file formats that cannot carry embedded
+// provenance (anything but PNG and JPEG).
+var ErrUnsupported = errors.New("unsupported file format (only PNG and JPEG can embed provenance)")
+
+// ErrNotFound is returned by Read when a file carries no fal provenance.
+var ErrNotFound = errors.New("no fal provenance found")
+
+// Info describes how a generated file was made.
+type Info struct {
+	Model     string `json:"model"`
+	Prompt    string `json:"prompt,omitempty"`
+	Seed      string `json:"seed,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"` // RFC 3339
+	Tool      string `json:"tool,omitempty"`
+}
+
+// fields returns the info as ordered key/value pairs, skipping empty values.
+func (i Info) fields() [][2]string {
+	all := [][2]string{
+		{"model", i.Model},
+		{"prompt", i.Prompt},
+		{"seed", i.Seed},
+		{"request_id", i.RequestID},
+		{"created_at", i.CreatedAt},
+		{"tool", i.Tool},
+	}
+	out := all[:0]
+	for _, f := range all {
+		if f[1] != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// set assigns a field by key; unknown keys are ignored.
+func (i *Info) set(key, value string) {
+	switch key {
+	case "model":
+		i.Model = value
+	case "prompt":
+		i.Prompt = value
+	case "seed":
+		i.Seed = value
+	case "request_id":
+		i.RequestID = value
+	case "created_at":
+		i.CreatedAt = value
+	case "tool":
+		i.Tool = value
+	}
+}
+
+var (
+	pngSignature = []byte("\x89PNG\r\n\x1a\n")
+	jpegSOI      = []byte{0xFF, 0xD8}
+)
+
+// Embed writes info into the image at path: tEXt/iTXt chunks for PNG, an
+// XMP packet for JPEG. Other formats return ErrUnsupported and are left
+// untouched.
+func Embed(path string, info Info) error {
+	if info.Tool == "" {
+		info.Tool = Tool
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	switch {
+	case bytes.HasPrefix(data, pngSignature):
+		out, err = embedPNG(data, info)
+	case bytes.HasPrefix(data, jpegSOI):
+		out, err = embedJPEG(data, info)
+	default:
+		return ErrUnsupported
+	}
+	if err != nil {
+		return err
+	}
+	return writeAtomic(path, out)
+}
+
+// Read returns the fal provenance embedded in the image at path.
+func Read(path string) (*Info, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var info *Info
+	switch {
+	case bytes.HasPrefix(data, pngSignature):
+		info, err = readPNG(data)
+	case bytes.HasPrefix(data, jpegSOI):
+		info, err = readJPEG(data)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, ErrNotFound
+	}
+	return info, nil
+}
+
+// SidecarPath returns the path of the JSON sidecar for a file.
+func SidecarPath(path string) string {
+	return path + ".json"
+}
+
+// writeAtomic replaces path with data, keeping its permissions.
+func writeAtomic(path string, data []byte) error {
+	st, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(path, data, st.Mode().Perm())
+}
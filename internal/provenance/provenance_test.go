@@ -0,0 +1,238 @@
+package provenance
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testJPEG returns a JPEG with a JFIF APP0 and an Exif APP1 segment, as
+// cameras and most editors write them.
+func testJPEG(t *testing.T) []byte {
+	t.Helper()
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, image.NewRGBA(image.Rect(0, 0, 8, 8)), nil); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	buf.Write(jpegSOI)
+	writeJPEGSegment(&buf, 0xE0, []byte("JFIF\x00\x01\x01\x00\x00\x01\x00\x01\x00\x00"))
+	writeJPEGSegment(&buf, 0xE1, append(append([]byte{}, exifHeader...), "MM\x00\x2a\x00\x00\x00\x08\x00\x00"...))
+	buf.Write(enc.Bytes()[len(jpegSOI):])
+	return buf.Bytes()
+}
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRoundTrip(t *testing.T) {
+	infos := map[string]Info{
+		"ascii": {Model: "fal-ai/flux/dev", Prompt: "a red fox, watercolor", Seed: "42",
+			RequestID: "req-1", CreatedAt: "2026-10-17T10:00:00Z"},
+		"utf-8": {Model: "fal-ai/nano-banana-2", Prompt: "un renard roux — 狐, <aquarelle> & \"encre\""},
+	}
+	formats := map[string]func(*testing.T) []byte{"x.png": testPNG, "x.jpg": testJPEG}
+
+	for name, mk := range formats {
+		for label, info := range infos {
+			t.Run(name+"/"+label, func(t *testing.T) {
+				path := writeTemp(t, name, mk(t))
+				if err := Embed(path, info); err != nil {
+					t.Fatal(err)
+				}
+				got, err := Read(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := info
+				want.Tool = Tool
+				if !reflect.DeepEqual(*got, want) {
+					t.Errorf("Read = %+v, want %+v", *got, want)
+				}
+				assertDecodes(t, path)
+			})
+		}
+	}
+}
+
+func TestPNGTextChunks(t *testing.T) {
+	path := writeTemp(t, "x.png", testPNG(t))
+	if err := Embed(path, Info{Model: "m", Prompt: "café"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	chunks, err := pngChunks(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := map[string]string{}
+	for _, c := range chunks {
+		if key, _, ok := parsePNGText(c); ok {
+			types[key] = c.typ
+		}
+	}
+	want := map[string]string{"fal:model": "tEXt", "fal:prompt": "iTXt", "fal:tool": "tEXt"}
+	if !reflect.DeepEqual(types, want) {
+		t.Errorf("text chunks = %v, want %v", types, want)
+	}
+	if chunks[0].typ != "IHDR" {
+		t.Errorf("first chunk is %s, want IHDR", chunks[0].typ)
+	}
+}
+
+func TestReembedReplaces(t *testing.T) {
+	for name, mk := range map[string]func(*testing.T) []byte{"x.png": testPNG, "x.jpg": testJPEG} {
+		t.Run(name, func(t *testing.T) {
+			path := writeTemp(t, name, mk(t))
+			if err := Embed(path, Info{Model: "first", Prompt: "one", Seed: "1"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := Embed(path, Info{Model: "second", Prompt: "two"}); err != nil {
+				t.Fatal(err)
+			}
+			twice, _ := os.ReadFile(path)
+
+			got, err := Read(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := (Info{Model: "second", Prompt: "two", Tool: Tool}); *got != want {
+				t.Errorf("Read = %+v, want %+v (no field left over from the first embed)", *got, want)
+			}
+			if n := countProvenance(t, twice); n != 1 {
+				t.Errorf("file holds %d provenance records, want 1", n)
+			}
+			assertDecodes(t, path)
+		})
+	}
+}
+
+func TestJPEGSegmentOrder(t *testing.T) {
+	path := writeTemp(t, "x.jpg", testJPEG(t))
+	if err := Embed(path, Info{Model: "m"}); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, s := range segments {
+		switch {
+		case s.marker == 0xE0:
+			order = append(order, "JFIF")
+		case s.marker == 0xE1 && bytes.HasPrefix(s.data, exifHeader):
+			order = append(order, "Exif")
+		case s.marker == 0xE1 && bytes.HasPrefix(s.data, xmpHeader):
+			order = append(order, "XMP")
+		}
+	}
+	if want := []string{"JFIF", "Exif", "XMP"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("segments = %v, want %v", order, want)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	pngData, jpgData := testPNG(t), testJPEG(t)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"x.png", pngData[:5]},               // inside the signature
+		{"x.png", pngData[:12]},              // inside the IHDR header
+		{"x.png", pngData[:len(pngData)/2]},  // inside IDAT
+		{"x.png", pngData[:len(pngData)-12]}, // IEND missing
+		{"x.jpg", jpgData[:3]},               // inside the first marker
+		{"x.jpg", jpgData[:10]},              // inside APP0
+		{"x.jpg", jpgData[:30]},              // inside the Exif APP1
+	}
+	for _, tt := range tests {
+		path := writeTemp(t, tt.name, tt.data)
+		if err := Embed(path, Info{Model: "m"}); err == nil {
+			t.Errorf("%s cut to %d bytes: Embed succeeded", tt.name, len(tt.data))
+		}
+		if after, _ := os.ReadFile(path); !bytes.Equal(after, tt.data) {
+			t.Errorf("%s cut to %d bytes: failed Embed modified the file", tt.name, len(tt.data))
+		}
+		if _, err := Read(path); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("%s cut to %d bytes: Read = %v, want a parse error", tt.name, len(tt.data), err)
+		}
+	}
+}
+
+func TestReadNotFound(t *testing.T) {
+	for name, mk := range map[string]func(*testing.T) []byte{"x.png": testPNG, "x.jpg": testJPEG} {
+		path := writeTemp(t, name, mk(t))
+		if _, err := Read(path); !errors.Is(err, ErrNotFound) {
+			t.Errorf("%s: Read = %v, want ErrNotFound", name, err)
+		}
+	}
+	path := writeTemp(t, "x.webp", []byte("RIFF\x00\x00\x00\x00WEBP"))
+	if err := Embed(path, Info{Model: "m"}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Embed of a WebP = %v, want ErrUnsupported", err)
+	}
+}
+
+// countProvenance counts the XMP segments of a JPEG, or the fal:model
+// chunks of a PNG.
+func countProvenance(t *testing.T, data []byte) int {
+	t.Helper()
+	n := 0
+	if bytes.HasPrefix(data, pngSignature) {
+		chunks, err := pngChunks(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range chunks {
+			if key, _, ok := parsePNGText(c); ok && key == pngKeywordPrefix+"model" {
+				n++
+			}
+		}
+		return n
+	}
+	segments, _, err := jpegSegments(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range segments {
+		if s.marker == 0xE1 && bytes.HasPrefix(s.data, xmpHeader) {
+			n++
+		}
+	}
+	return n
+}
+
+// assertDecodes checks that the image at path is still a valid image.
+func assertDecodes(t *testing.T, path string) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, _, err := image.Decode(f); err != nil {
+		t.Errorf("%s no longer decodes: %v", filepath.Base(path), err)
+	}
+}
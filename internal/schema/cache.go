@@ -0,0 +1,89 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/config"
+)
+
+// MaxAge is how long a cached OpenAPI document is used before it is
+// fetched again.
+const MaxAge = 24 * time.Hour
+
+// cached is the on-disk form of a cached OpenAPI document.
+type cached struct {
+	EndpointID string          `json:"endpoint_id"`
+	FetchedAt  time.Time       `json:"fetched_at"`
+	OpenAPI    json.RawMessage `json:"openapi"`
+}
+
+// Dir returns the directory holding cached OpenAPI documents.
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "schemas"), nil
+}
+
+func cachePath(endpointID string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	name := strings.NewReplacer("/", "__", "\\", "__", ":", "_").Replace(endpointID)
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Load returns the cached OpenAPI document of endpointID and when it was
+// fetched. It returns an error wrapping os.ErrNotExist when none is cached.
+func Load(endpointID string) (openapi []byte, fetchedAt time.Time, err error) {
+	path, err := cachePath(endpointID)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var c cached
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, time.Time{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if len(c.OpenAPI) == 0 {
+		return nil, time.Time{}, fmt.Errorf("%s: %w", path, os.ErrNotExist)
+	}
+	return c.OpenAPI, c.FetchedAt, nil
+}
+
+// Store caches the OpenAPI document of endpointID.
+func Store(endpointID string, openapi []byte) error {
+	path, err := cachePath(endpointID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cached{
+		EndpointID: endpointID,
+		FetchedAt:  time.Now().UTC(),
+		OpenAPI:    openapi,
+	})
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFileAtomic(path, data, 0600)
+}
+
+// IsNotCached reports whether err means no document is cached.
+func IsNotCached(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
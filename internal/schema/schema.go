@@ -0,0 +1,304 @@
+// Package schema parses the OpenAPI documents fal publishes for each model
+// endpoint and validates request payloads against their input schema.
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNoInput is returned when an OpenAPI document has no JSON request body
+// for the endpoint.
+var ErrNoInput = errors.New("OpenAPI document has no input schema")
+
+// Schema is the subset of JSON Schema used by fal model endpoints.
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        Type               `json:"type,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Const       any                `json:"const,omitempty"`
+	Default     any                `json:"default,omitempty"`
+	Examples    []any              `json:"examples,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+	AnyOf       []*Schema          `json:"anyOf,omitempty"`
+	OneOf       []*Schema          `json:"oneOf,omitempty"`
+	AllOf       []*Schema          `json:"allOf,omitempty"`
+
+	// AdditionalProperties is kept raw: it may be a boolean or a schema.
+	AdditionalProperties json.RawMessage `json:"additionalProperties,omitempty"`
+
+	// Order is fal's preferred display order of the properties.
+	Order []string `json:"x-fal-order-properties,omitempty"`
+}
+
+// Type is a JSON Schema "type": a single name or a list of names.
+type Type []string
+
+func (t *Type) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = Type{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+func (t Type) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// Has reports whether name is one of the types.
+func (t Type) Has(name string) bool {
+	for _, n := range t {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Endpoint holds the input and output schemas of one model endpoint.
+// Both may contain $ref nodes; use Resolve to follow them.
+type Endpoint struct {
+	ID          string             `json:"endpoint_id"`
+	Input       *Schema            `json:"input"`
+	Output      *Schema            `json:"output,omitempty"`
+	Definitions map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// Parse extracts the schemas of endpointID from an OpenAPI document.
+func Parse(endpointID string, openapi []byte) (*Endpoint, error) {
+	type content map[string]struct {
+		Schema *Schema `json:"schema"`
+	}
+	type operation struct {
+		RequestBody struct {
+			Content content `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]struct {
+			Content content `json:"content"`
+		} `json:"responses"`
+	}
+	var doc struct {
+		Paths      map[string]map[string]operation `json:"paths"`
+		Components struct {
+			Schemas map[string]*Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(openapi, &doc); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+
+	e := &Endpoint{ID: endpointID, Definitions: doc.Components.Schemas}
+
+	// The submit operation is POST /<endpoint-id>; fall back to the only
+	// POST without path parameters for documents that name it differently.
+	post, ok := doc.Paths["/"+endpointID]["post"]
+	if !ok {
+		paths := make([]string, 0, len(doc.Paths))
+		for p, ops := range doc.Paths {
+			if _, has := ops["post"]; has && !strings.Contains(p, "{") {
+				paths = append(paths, p)
+			}
+		}
+		sort.Strings(paths)
+		if len(paths) == 0 {
+			return nil, ErrNoInput
+		}
+		post = doc.Paths[paths[0]]["post"]
+	}
+	e.Input = post.RequestBody.Content["application/json"].Schema
+	if e.Input == nil {
+		return nil, ErrNoInput
+	}
+	if r, ok := post.Responses["200"]; ok {
+		e.Output = r.Content["application/json"].Schema
+	}
+	if e.Output == nil {
+		if get, ok := doc.Paths["/"+endpointID+"/requests/{request_id}"]["get"]; ok {
+			e.Output = get.Responses["200"].Content["application/json"].Schema
+		}
+	}
+	return e, nil
+}
+
+// Resolve follows $ref pointers to #/components/schemas/... and returns the
+// schema they point to. Unresolvable references return s unchanged.
+func (e *Endpoint) Resolve(s *Schema) *Schema {
+	for i := 0; s != nil && s.Ref != "" && i < 32; i++ {
+		name := s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+		target, ok := e.Definitions[name]
+		if !ok {
+			return s
+		}
+		s = target
+	}
+	return s
+}
+
+// Field is one property of an object schema.
+type Field struct {
+	Name     string
+	Schema   *Schema // resolved
+	Required bool
+}
+
+// Fields returns the properties of an object schema, in fal's display order
+// when the schema defines one, otherwise required fields first, then by name.
+func (e *Endpoint) Fields(s *Schema) []Field {
+	s = e.Resolve(s)
+	if s == nil {
+		return nil
+	}
+	required := map[string]bool{}
+	for _, r := range s.Required {
+		required[r] = true
+	}
+
+	rank := map[string]int{}
+	for i, name := range s.Order {
+		rank[name] = i + 1
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(a, b int) bool {
+		ra, rb := rank[names[a]], rank[names[b]]
+		switch {
+		case ra != 0 && rb != 0:
+			return ra < rb
+		case ra != 0 || rb != 0:
+			return ra != 0
+		case required[names[a]] != required[names[b]]:
+			return required[names[a]]
+		}
+		return names[a] < names[b]
+	})
+
+	fields := make([]Field, len(names))
+	for i, name := range names {
+		fields[i] = Field{Name: name, Schema: e.Resolve(s.Properties[name]), Required: required[name]}
+	}
+	return fields
+}
+
+// TypeName describes the accepted type(s) of s for display, e.g. "integer",
+// "string[]", "ImageSize | string".
+func (e *Endpoint) TypeName(s *Schema) string {
+	title := ""
+	if s != nil && s.Ref != "" {
+		title = s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	}
+	s = e.Resolve(s)
+	if s == nil {
+		return "any"
+	}
+	if alts := append(append([]*Schema{}, s.AnyOf...), s.OneOf...); len(alts) > 0 {
+		var names []string
+		seen := map[string]bool{}
+		for _, a := range alts {
+			n := e.TypeName(a)
+			if n == "null" || seen[n] {
+				continue
+			}
+			seen[n] = true
+			names = append(names, n)
+		}
+		return strings.Join(names, " | ")
+	}
+	if len(s.AllOf) == 1 {
+		return e.TypeName(s.AllOf[0])
+	}
+	switch {
+	case s.Type.Has("array"):
+		return e.TypeName(s.Items) + "[]"
+	case s.Type.Has("object") || (len(s.Type) == 0 && s.Properties != nil):
+		if title != "" {
+			return title
+		}
+		if s.Title != "" {
+			return s.Title
+		}
+		return "object"
+	case len(s.Type) > 0:
+		var names []string
+		for _, t := range s.Type {
+			if t != "null" {
+				names = append(names, t)
+			}
+		}
+		return strings.Join(names, " | ")
+	}
+	return "any"
+}
+
+// Inline returns a copy of s with every $ref replaced by the schema it
+// points to, so it can be printed on its own. A reference that would recurse
+// into itself is left as a $ref.
+func (e *Endpoint) Inline(s *Schema) *Schema {
+	return e.inline(s, map[string]bool{})
+}
+
+func (e *Endpoint) inline(s *Schema, active map[string]bool) *Schema {
+	if s == nil {
+		return nil
+	}
+	if s.Ref != "" {
+		if active[s.Ref] {
+			return s
+		}
+		target := e.Resolve(s)
+		if target == s {
+			return s
+		}
+		active[s.Ref] = true
+		defer delete(active, s.Ref)
+		return e.inline(target, active)
+	}
+
+	out := *s
+	if s.Properties != nil {
+		out.Properties = make(map[string]*Schema, len(s.Properties))
+		for name, p := range s.Properties {
+			out.Properties[name] = e.inline(p, active)
+		}
+	}
+	out.Items = e.inline(s.Items, active)
+	out.AnyOf = e.inlineAll(s.AnyOf, active)
+	out.OneOf = e.inlineAll(s.OneOf, active)
+	out.AllOf = e.inlineAll(s.AllOf, active)
+	return &out
+}
+
+func (e *Endpoint) inlineAll(list []*Schema, active map[string]bool) []*Schema {
+	if list == nil {
+		return nil
+	}
+	out := make([]*Schema, len(list))
+	for i, s := range list {
+		out[i] = e.inline(s, active)
+	}
+	return out
+}
@@ -0,0 +1,254 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/the20100/fal-cli/internal/textdist"
+)
+
+// Problem is one way a payload does not match the input schema.
+type Problem struct {
+	Field   string `json:"field,omitempty"` // dotted path, e.g. "image_size.width"
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	if p.Field == "" {
+		return p.Message
+	}
+	return p.Field + ": " + p.Message
+}
+
+// Validate checks payload against the endpoint's input schema and returns
+// every problem found, sorted by field. Fields the schema does not declare
+// are reported as unknown, with a suggestion when one is close.
+//
+// Validation is deliberately lenient where fal's schemas are imprecise:
+// null is accepted for any field, and anything the schema cannot describe
+// (unresolvable $refs, untyped fields) passes.
+func (e *Endpoint) Validate(payload map[string]any) []Problem {
+	// Work on the JSON form, so Go values built in code (int, []string, ...)
+	// compare like decoded ones.
+	var v any = payload
+	if data, err := json.Marshal(payload); err == nil {
+		_ = json.Unmarshal(data, &v)
+	}
+	var problems []Problem
+	e.validate(e.Input, v, "", &problems)
+	sort.SliceStable(problems, func(a, b int) bool { return problems[a].Field < problems[b].Field })
+	return problems
+}
+
+func (e *Endpoint) validate(s *Schema, v any, path string, out *[]Problem) {
+	s = e.Resolve(s)
+	if s == nil || s.Ref != "" || v == nil {
+		return
+	}
+	report := func(format string, args ...any) {
+		*out = append(*out, Problem{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, sub := range s.AllOf {
+		e.validate(sub, v, path, out)
+	}
+	if alts := append(append([]*Schema{}, s.AnyOf...), s.OneOf...); len(alts) > 0 {
+		e.validateAlternatives(alts, v, path, out)
+		return
+	}
+
+	if len(s.Type) > 0 && !typeMatches(s.Type, v) {
+		report("expected %s, got %s", e.TypeName(s), jsonType(v))
+		return
+	}
+	if len(s.Enum) > 0 && !containsValue(s.Enum, v) {
+		report("%s is not one of %s", formatValue(v), formatValues(s.Enum))
+		return
+	}
+	if s.Const != nil && !equalValues(s.Const, v) {
+		report("must be %s", formatValue(s.Const))
+		return
+	}
+
+	switch t := v.(type) {
+	case float64:
+		switch {
+		case s.Minimum != nil && s.Maximum != nil && (t < *s.Minimum || t > *s.Maximum):
+			report("%s is out of range (%s to %s)", formatValue(t), formatValue(*s.Minimum), formatValue(*s.Maximum))
+		case s.Minimum != nil && t < *s.Minimum:
+			report("%s is below the minimum of %s", formatValue(t), formatValue(*s.Minimum))
+		case s.Maximum != nil && t > *s.Maximum:
+			report("%s is above the maximum of %s", formatValue(t), formatValue(*s.Maximum))
+		}
+	case []any:
+		if s.MinItems != nil && len(t) < *s.MinItems {
+			report("needs at least %d item(s), got %d", *s.MinItems, len(t))
+		}
+		if s.MaxItems != nil && len(t) > *s.MaxItems {
+			report("takes at most %d item(s), got %d", *s.MaxItems, len(t))
+		}
+		for i, item := range t {
+			e.validate(s.Items, item, join(path, strconv.Itoa(i)), out)
+		}
+	case map[string]any:
+		e.validateObject(s, t, path, out)
+	}
+}
+
+func (e *Endpoint) validateObject(s *Schema, obj map[string]any, path string, out *[]Problem) {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			*out = append(*out, Problem{Field: join(path, name), Message: "required field is missing"})
+		}
+	}
+
+	extraAllowed := len(s.Properties) == 0 || (len(s.AdditionalProperties) > 0 && string(s.AdditionalProperties) != "false")
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	for key, value := range obj {
+		prop, ok := s.Properties[key]
+		if ok {
+			e.validate(prop, value, join(path, key), out)
+			continue
+		}
+		if extraAllowed {
+			continue
+		}
+		msg := "unknown field"
+		if guess := Suggest(key, names); guess != "" {
+			msg += fmt.Sprintf(" (did you mean %q?)", guess)
+		}
+		*out = append(*out, Problem{Field: join(path, key), Message: msg})
+	}
+}
+
+// validateAlternatives accepts v if it matches any of alts. Otherwise the
+// problems of the alternative(s) of the same JSON type as v are reported,
+// which usually pinpoints the mistake (e.g. a bad enum value) better than
+// a generic "does not match".
+func (e *Endpoint) validateAlternatives(alts []*Schema, v any, path string, out *[]Problem) {
+	var sameType [][]Problem
+	var names []string
+	for _, alt := range alts {
+		var problems []Problem
+		e.validate(alt, v, path, &problems)
+		if len(problems) == 0 {
+			return
+		}
+		r := e.Resolve(alt)
+		if r != nil && len(r.Type) > 0 && typeMatches(r.Type, v) {
+			sameType = append(sameType, problems)
+		}
+		if n := e.TypeName(alt); n != "null" {
+			names = append(names, n)
+		}
+	}
+	if len(sameType) == 1 {
+		*out = append(*out, sameType[0]...)
+		return
+	}
+	*out = append(*out, Problem{Field: path, Message: fmt.Sprintf("expected %s, got %s", strings.Join(names, " | "), jsonType(v))})
+}
+
+// Suggest returns the candidate closest to name, or "" if none is close
+// enough to be a likely typo.
+func Suggest(name string, candidates []string) string {
+	best, bestDist := "", math.MaxInt
+	lower := strings.ToLower(name)
+	for _, c := range candidates {
+		d := textdist.Levenshtein(lower, strings.ToLower(c))
+		if d < bestDist || (d == bestDist && c < best) {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	limit := max(2, len(name)/3)
+	if bestDist <= limit {
+		return best
+	}
+	return ""
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// jsonType names the JSON type of a decoded value.
+func jsonType(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if t == math.Trunc(t) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func typeMatches(t Type, v any) bool {
+	got := jsonType(v)
+	for _, want := range t {
+		if want == got || (want == "number" && got == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsValue(values []any, v any) bool {
+	for _, x := range values {
+		if equalValues(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func equalValues(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+func formatValue(v any) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func formatValues(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			parts[i] = s
+		} else {
+			parts[i] = formatValue(v)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
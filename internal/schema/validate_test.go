@@ -0,0 +1,182 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+// testOpenAPI is trimmed from the OpenAPI document of a fal text-to-image
+// endpoint.
+const testOpenAPI = `{
+  "paths": {
+    "/fal-ai/test": {
+      "post": {
+        "requestBody": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/TestInput"}}}},
+        "responses": {"200": {"content": {"application/json": {"schema": {"$ref": "#/components/schemas/TestOutput"}}}}}
+      }
+    }
+  },
+  "components": {
+    "schemas": {
+      "TestInput": {
+        "type": "object",
+        "required": ["prompt"],
+        "properties": {
+          "prompt": {"type": "string"},
+          "num_images": {"type": "integer", "minimum": 1, "maximum": 4},
+          "guidance_scale": {"type": "number", "minimum": 0},
+          "seed": {"anyOf": [{"type": "integer"}, {"type": "null"}]},
+          "output_format": {"type": "string", "enum": ["jpeg", "png"]},
+          "sync_mode": {"type": "boolean"},
+          "image_size": {"anyOf": [
+            {"$ref": "#/components/schemas/ImageSize"},
+            {"type": "string", "enum": ["square_hd", "landscape_16_9"]}
+          ]},
+          "image_urls": {"type": "array", "items": {"type": "string"}, "maxItems": 2},
+          "loras": {"type": "array", "items": {"$ref": "#/components/schemas/LoraWeight"}},
+          "extra": {"type": "object"}
+        }
+      },
+      "ImageSize": {
+        "type": "object",
+        "properties": {
+          "width": {"type": "integer", "maximum": 14142},
+          "height": {"type": "integer", "maximum": 14142}
+        }
+      },
+      "LoraWeight": {
+        "type": "object",
+        "required": ["path"],
+        "properties": {"path": {"type": "string"}, "scale": {"type": "number"}}
+      },
+      "TestOutput": {"type": "object", "properties": {"images": {"type": "array"}}}
+    }
+  }
+}`
+
+func TestValidate(t *testing.T) {
+	e, err := Parse("fal-ai/test", []byte(testOpenAPI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		payload map[string]any
+		want    []Problem
+	}{
+		{
+			name: "valid",
+			payload: map[string]any{
+				"prompt": "a cat", "num_images": 2, "guidance_scale": 3.5, "seed": nil,
+				"output_format": "png", "sync_mode": true,
+				"image_size": map[string]any{"width": 1024, "height": 768},
+				"image_urls": []string{"https://example.com/a.png"},
+				"loras":      []any{map[string]any{"path": "x", "scale": 1}},
+				"extra":      map[string]any{"anything": "goes"},
+			},
+		},
+		{
+			name:    "image_size preset",
+			payload: map[string]any{"prompt": "a cat", "image_size": "square_hd"},
+		},
+		{
+			name:    "missing required",
+			payload: map[string]any{"num_images": 1},
+			want:    []Problem{{Field: "prompt", Message: "required field is missing"}},
+		},
+		{
+			name:    "unknown field with suggestion",
+			payload: map[string]any{"prompt": "a cat", "num_image": 1},
+			want:    []Problem{{Field: "num_image", Message: `unknown field (did you mean "num_images"?)`}},
+		},
+		{
+			name:    "unknown field without suggestion",
+			payload: map[string]any{"prompt": "a cat", "negative": "dogs"},
+			want:    []Problem{{Field: "negative", Message: "unknown field"}},
+		},
+		{
+			name:    "wrong type",
+			payload: map[string]any{"prompt": 42},
+			want:    []Problem{{Field: "prompt", Message: "expected string, got integer"}},
+		},
+		{
+			name:    "integer expected",
+			payload: map[string]any{"prompt": "a cat", "num_images": 1.5},
+			want:    []Problem{{Field: "num_images", Message: "expected integer, got number"}},
+		},
+		{
+			name:    "out of range",
+			payload: map[string]any{"prompt": "a cat", "num_images": 8},
+			want:    []Problem{{Field: "num_images", Message: "8 is out of range (1 to 4)"}},
+		},
+		{
+			name:    "below minimum",
+			payload: map[string]any{"prompt": "a cat", "guidance_scale": -1},
+			want:    []Problem{{Field: "guidance_scale", Message: "-1 is below the minimum of 0"}},
+		},
+		{
+			name:    "enum",
+			payload: map[string]any{"prompt": "a cat", "output_format": "webp"},
+			want:    []Problem{{Field: "output_format", Message: `"webp" is not one of jpeg, png`}},
+		},
+		{
+			name:    "alternative of the same type",
+			payload: map[string]any{"prompt": "a cat", "image_size": "square"},
+			want:    []Problem{{Field: "image_size", Message: `"square" is not one of square_hd, landscape_16_9`}},
+		},
+		{
+			name:    "nested field",
+			payload: map[string]any{"prompt": "a cat", "image_size": map[string]any{"width": 20000}},
+			want:    []Problem{{Field: "image_size.width", Message: "20000 is above the maximum of 14142"}},
+		},
+		{
+			name:    "too many items",
+			payload: map[string]any{"prompt": "a cat", "image_urls": []string{"a", "b", "c"}},
+			want:    []Problem{{Field: "image_urls", Message: "takes at most 2 item(s), got 3"}},
+		},
+		{
+			name:    "array items",
+			payload: map[string]any{"prompt": "a cat", "loras": []any{map[string]any{"path": "x"}, map[string]any{"scale": 1}}},
+			want:    []Problem{{Field: "loras.1.path", Message: "required field is missing"}},
+		},
+		{
+			name:    "sorted by field",
+			payload: map[string]any{"sync_mode": "yes", "num_images": 0},
+			want: []Problem{
+				{Field: "num_images", Message: "0 is out of range (1 to 4)"},
+				{Field: "prompt", Message: "required field is missing"},
+				{Field: "sync_mode", Message: "expected boolean, got string"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := e.Validate(tt.payload)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate =\n  %v\nwant\n  %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"num_images", "image_size", "seed", "prompt"}
+	tests := []struct {
+		name, want string
+	}{
+		{"num_image", "num_images"},
+		{"Image_Size", "image_size"},
+		{"sed", "seed"},
+		{"promt", "prompt"},
+		{"negative_prompt", ""},
+		{"xyz", ""},
+	}
+	for _, tt := range tests {
+		if got := Suggest(tt.name, candidates); got != tt.want {
+			t.Errorf("Suggest(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := Suggest("seed", nil); got != "" {
+		t.Errorf("Suggest with no candidates = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,196 @@
+// Package storage uploads files to S3-compatible object stores (R2, S3,
+// MinIO, ...) with plain HTTP requests signed with AWS Signature V4.
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultPrefix is the key prefix used when none is configured. Cleanup
+// only ever touches keys under the prefix.
+const DefaultPrefix = "fal-tmp/"
+
+// Config describes a bucket and how to reach it.
+type Config struct {
+	Endpoint        string // e.g. https://<account>.r2.cloudflarestorage.com
+	Region          string // "auto" for R2
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	PublicURL       string // base URL objects are publicly served from
+	Prefix          string // key prefix for uploads
+}
+
+// Client talks to one bucket using path-style requests
+// ({endpoint}/{bucket}/{key}), which every S3-compatible store supports.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+// Object is an entry of a bucket listing.
+type Object struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// New validates cfg, fills in defaults and returns a client.
+func New(cfg Config) (*Client, error) {
+	var missing []string
+	if cfg.Bucket == "" {
+		missing = append(missing, "bucket")
+	}
+	if cfg.AccessKeyID == "" {
+		missing = append(missing, "access key ID")
+	}
+	if cfg.SecretAccessKey == "" {
+		missing = append(missing, "secret access key")
+	}
+	if cfg.Endpoint == "" && cfg.Region == "" {
+		missing = append(missing, "endpoint or region")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("storage is not configured: missing %s", strings.Join(missing, ", "))
+	}
+
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	if !strings.Contains(cfg.Endpoint, "://") {
+		cfg.Endpoint = "https://" + cfg.Endpoint
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+		if strings.Contains(cfg.Endpoint, ".r2.cloudflarestorage.com") {
+			cfg.Region = "auto"
+		}
+	}
+	if cfg.PublicURL != "" && !strings.Contains(cfg.PublicURL, "://") {
+		cfg.PublicURL = "https://" + cfg.PublicURL
+	}
+	cfg.PublicURL = strings.TrimRight(cfg.PublicURL, "/")
+	if cfg.Prefix == "" {
+		cfg.Prefix = DefaultPrefix
+	}
+
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: 10 * time.Minute}}, nil
+}
+
+// Bucket returns the bucket name.
+func (c *Client) Bucket() string { return c.cfg.Bucket }
+
+// Prefix returns the key prefix for uploads.
+func (c *Client) Prefix() string { return c.cfg.Prefix }
+
+// URL returns the public URL of key: under PublicURL when set, otherwise
+// the path-style object URL on the endpoint.
+func (c *Client) URL(key string) string {
+	if c.cfg.PublicURL != "" {
+		return c.cfg.PublicURL + "/" + uriEncode(key, false)
+	}
+	return c.objectURL(key)
+}
+
+func (c *Client) objectURL(key string) string {
+	return c.cfg.Endpoint + "/" + uriEncode(c.cfg.Bucket, true) + "/" + uriEncode(key, false)
+}
+
+// Put uploads data to key.
+func (c *Client) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectURL(key), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	_, err = c.do(req, hexSHA256(data))
+	return err
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (c *Client) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	_, err = c.do(req, emptySHA256)
+	return err
+}
+
+// List returns every object whose key starts with prefix.
+func (c *Client) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		q := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+		u := c.cfg.Endpoint + "/" + uriEncode(c.cfg.Bucket, true) + "?" + q.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := c.do(req, emptySHA256)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Contents []struct {
+				Key          string
+				Size         int64
+				LastModified time.Time
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := xml.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("parsing bucket listing: %w", err)
+		}
+		for _, o := range page.Contents {
+			objects = append(objects, Object{Key: o.Key, Size: o.Size, LastModified: o.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// do signs and sends req and returns the response body. Error responses
+// are turned into errors carrying the S3 error code and message.
+func (c *Client) do(req *http.Request, payloadHash string) ([]byte, error) {
+	sign(req, payloadHash, c.cfg.Region, c.cfg.AccessKeyID, c.cfg.SecretAccessKey, time.Now())
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Code    string
+			Message string
+		}
+		if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+			return nil, fmt.Errorf("%s %s: HTTP %d %s: %s", req.Method, req.URL.Path, resp.StatusCode, e.Code, e.Message)
+		}
+		return nil, fmt.Errorf("%s %s: HTTP %d", req.Method, req.URL.Path, resp.StatusCode)
+	}
+	return body, nil
+}
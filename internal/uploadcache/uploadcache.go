@@ -0,0 +1,242 @@
+package uploadcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/config"
+	"github.com/the20100/fal-cli/internal/filelock"
+)
+
+// Default lifetimes of cached URLs. fal storage does not guarantee how long
+// uploaded files stay available, so its URLs are kept for a day only;
+// bucket objects live until "fal storage cleanup" removes them (7 days by
+// default).
+const (
+	FalTTL     = 24 * time.Hour
+	StorageTTL = 7 * 24 * time.Hour
+)
+
+// minRemaining is how long a URL must still be valid to be reused, so it
+// does not expire while a queued request is waiting to run.
+const minRemaining = time.Hour
+
+// Entry is one uploaded file.
+type Entry struct {
+	Hash       string    `json:"sha256"`
+	Backend    string    `json:"backend"` // "fal" or "s3:<bucket>"
+	URL        string    `json:"url"`
+	Key        string    `json:"key,omitempty"` // object key, for bucket uploads
+	Name       string    `json:"name"`          // base name of the uploaded file
+	Size       int64     `json:"size"`
+	UploadedAt time.Time `json:"uploaded_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the entry can no longer be reused at t.
+func (e Entry) Expired(t time.Time) bool {
+	return !t.Add(minRemaining).Before(e.ExpiresAt)
+}
+
+// mu serializes cache updates within the process; lockCache does across
+// processes.
+var mu sync.Mutex
+
+// Path returns the cache file path.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "uploads.json"), nil
+}
+
+// HashFile returns the hex SHA-256 of the file at path.
+func HashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// List returns all cache entries, most recently uploaded first.
+func List() ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load()
+}
+
+// Lookup returns the cached URL for content hash on backend, if one exists
+// and is still valid.
+func Lookup(hash, backend string) (*Entry, bool) {
+	all, err := List()
+	if err != nil {
+		return nil, false
+	}
+	now := time.Now()
+	for _, e := range all {
+		if e.Hash == hash && e.Backend == backend && !e.Expired(now) {
+			return &e, true
+		}
+	}
+	return nil, false
+}
+
+// Record stores e, replacing any entry for the same content and backend.
+// Entries pointing at the same URL with other content are dropped too,
+// since the object behind that URL has been overwritten.
+func Record(e Entry) error {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockCache()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := load()
+	if err != nil {
+		return err
+	}
+	kept := []Entry{e}
+	for _, old := range all {
+		if (old.Hash == e.Hash && old.Backend == e.Backend) || old.URL == e.URL {
+			continue
+		}
+		kept = append(kept, old)
+	}
+	return save(kept)
+}
+
+// Forget removes the entries of backend for the given object keys, for
+// files that have been deleted from a bucket.
+func Forget(backend string, keys []string) error {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockCache()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := load()
+	if err != nil {
+		return err
+	}
+	gone := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		gone[k] = true
+	}
+	var kept []Entry
+	for _, e := range all {
+		if e.Backend != backend || !gone[e.Key] {
+			kept = append(kept, e)
+		}
+	}
+	if len(kept) == len(all) {
+		return nil
+	}
+	return save(kept)
+}
+
+// Prune removes expired entries, or every entry when all is set, and
+// returns how many were removed.
+func Prune(all bool) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	unlock, err := lockCache()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	entries, err := load()
+	if err != nil {
+		return 0, err
+	}
+	now := time.Now()
+	var kept []Entry
+	for _, e := range entries {
+		if !all && !e.Expired(now) {
+			kept = append(kept, e)
+		}
+	}
+	if removed := len(entries) - len(kept); removed > 0 {
+		return removed, save(kept)
+	}
+	return 0, nil
+}
+
+// lockCache takes the lock file guarding load+save against other
+// processes.
+func lockCache() (func(), error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("locking %s: %w", path, err)
+	}
+	return unlock, nil
+}
+
+// load reads the cache. A missing file is an empty cache.
+func load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var all []Entry
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	sort.SliceStable(all, func(a, b int) bool {
+		return all[a].UploadedAt.After(all[b].UploadedAt)
+	})
+	return all, nil
+}
+
+// save writes the cache atomically.
+func save(all []Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if all == nil {
+		all = []Entry{}
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFileAtomic(path, data, 0600)
+}
@@ -0,0 +1,234 @@
+// Package usage records the estimated cost of every request submitted
+// through the CLI and enforces the spending caps of config.Budget.
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/the20100/fal-cli/internal/config"
+	"github.com/the20100/fal-cli/internal/filelock"
+)
+
+// keepFor bounds how far back entries are kept; older ones are dropped
+// when a new entry is recorded.
+const keepFor = 400 * 24 * time.Hour
+
+// Entry is the estimated cost of one submitted request.
+type Entry struct {
+	At       time.Time `json:"at"`
+	ModelID  string    `json:"model_id"`
+	Command  string    `json:"command,omitempty"`
+	Cost     float64   `json:"cost"`
+	Currency string    `json:"currency,omitempty"`
+}
+
+// Totals is the estimated spend of the current day and month (local time).
+type Totals struct {
+	Day        float64 `json:"day"`
+	Month      float64 `json:"month"`
+	DayCount   int     `json:"day_requests"`
+	MonthCount int     `json:"month_requests"`
+}
+
+// Cap names, as reported by OverBudgetError.
+const (
+	CapDaily   = "daily"
+	CapMonthly = "monthly"
+	CapRequest = "per-command"
+)
+
+// OverBudgetError is returned by Charge and Check when a request would
+// exceed a cap.
+type OverBudgetError struct {
+	Cap   string  // CapDaily, CapMonthly or CapRequest
+	Limit float64 // the cap
+	Spent float64 // spend so far in the cap's period (0 for CapRequest)
+	Cost  float64 // estimated cost of the request
+}
+
+func (e *OverBudgetError) Error() string {
+	if e.Cap == CapRequest {
+		return fmt.Sprintf("estimated cost %.4f exceeds the per-command budget of %.2f", e.Cost, e.Limit)
+	}
+	return fmt.Sprintf("estimated cost %.4f would exceed the %s budget: %.4f of %.2f already spent",
+		e.Cost, e.Cap, e.Spent, e.Limit)
+}
+
+// mu serializes usage updates within the process (batch runs submit from
+// several goroutines); a lock file does the same across processes.
+var mu sync.Mutex
+
+// Path returns the usage file path.
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "usage.json"), nil
+}
+
+// List returns all recorded entries, oldest first.
+func List() ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	return load()
+}
+
+// Sum returns the spend of the day and month containing now.
+func Sum(now time.Time) (Totals, error) {
+	all, err := List()
+	if err != nil {
+		return Totals{}, err
+	}
+	return sum(all, now), nil
+}
+
+// Check reports whether e would fit within b, without recording it. A nil
+// budget or a zero cap means no limit.
+func Check(e Entry, b *config.Budget) error {
+	all, err := List()
+	if err != nil {
+		return err
+	}
+	return check(all, e, b)
+}
+
+// Charge records e if it fits within b, or unconditionally with force. The
+// check and the write happen under one lock, held across processes, so
+// concurrent submissions cannot overrun a cap together.
+func Charge(e Entry, b *config.Budget, force bool) error {
+	return update(func(all []Entry) ([]Entry, error) {
+		if !force {
+			if err := check(all, e, b); err != nil {
+				return nil, err
+			}
+		}
+		cutoff := e.At.Add(-keepFor)
+		kept := make([]Entry, 0, len(all)+1)
+		for _, old := range all {
+			if old.At.After(cutoff) {
+				kept = append(kept, old)
+			}
+		}
+		return append(kept, e), nil
+	})
+}
+
+// Refund removes an entry recorded by Charge, for a request that was
+// not submitted after all.
+func Refund(e Entry) error {
+	return update(func(all []Entry) ([]Entry, error) {
+		for i := len(all) - 1; i >= 0; i-- {
+			if all[i].At.Equal(e.At) && all[i].ModelID == e.ModelID && all[i].Cost == e.Cost {
+				return append(all[:i], all[i+1:]...), nil
+			}
+		}
+		return all, nil
+	})
+}
+
+// update loads the entries, applies fn and saves the result, holding both
+// the process mutex and the lock file.
+func update(fn func(all []Entry) ([]Entry, error)) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	unlock, err := filelock.Lock(path + ".lock")
+	if err != nil {
+		return fmt.Errorf("locking %s: %w", path, err)
+	}
+	defer unlock()
+
+	all, err := load()
+	if err != nil {
+		return err
+	}
+	if all, err = fn(all); err != nil {
+		return err
+	}
+	return save(all)
+}
+
+func check(all []Entry, e Entry, b *config.Budget) error {
+	if b == nil {
+		return nil
+	}
+	if b.PerCommand > 0 && e.Cost > b.PerCommand {
+		return &OverBudgetError{Cap: CapRequest, Limit: b.PerCommand, Cost: e.Cost}
+	}
+	t := sum(all, e.At)
+	if b.Daily > 0 && t.Day+e.Cost > b.Daily {
+		return &OverBudgetError{Cap: CapDaily, Limit: b.Daily, Spent: t.Day, Cost: e.Cost}
+	}
+	if b.Monthly > 0 && t.Month+e.Cost > b.Monthly {
+		return &OverBudgetError{Cap: CapMonthly, Limit: b.Monthly, Spent: t.Month, Cost: e.Cost}
+	}
+	return nil
+}
+
+func sum(all []Entry, now time.Time) Totals {
+	now = now.Local()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	var t Totals
+	for _, e := range all {
+		if e.At.Before(month) {
+			continue
+		}
+		t.Month += e.Cost
+		t.MonthCount++
+		if !e.At.Before(day) {
+			t.Day += e.Cost
+			t.DayCount++
+		}
+	}
+	return t
+}
+
+// load reads the usage file. A missing file means no usage.
+func load() ([]Entry, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var all []Entry
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return all, nil
+}
+
+// save writes the usage file atomically.
+func save(all []Entry) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return config.WriteFileAtomic(path, data, 0600)
+}